@@ -1,9 +1,14 @@
 package main
 
 import (
-	"go.uber.org/zap"
-	
+	"context"
 	"github.com/coreyvan/backend-takehome/internal/app"
+	"github.com/coreyvan/backend-takehome/internal/config"
+	"github.com/coreyvan/backend-takehome/internal/ingest"
+	"go.uber.org/zap"
+	"os"
+	"os/signal"
+	"syscall"
 )
 
 func main() {
@@ -12,7 +17,35 @@ func main() {
 		panic(err)
 	}
 
-	if err := app.Run(log); err != nil {
+	cfg, args, err := config.Load("telegraph-api", os.Args[1:])
+	if err != nil {
+		log.Sugar().Fatalf("loading config: %v", err)
+	}
+
+	// The port can still be given positionally, as in `task api -- 3000`.
+	if len(args) > 0 {
+		cfg.HTTP.Port = args[0]
+		if err := cfg.Validate(); err != nil {
+			log.Sugar().Fatalf("loading config: %v", err)
+		}
+	}
+
+	// SIGINT and SIGTERM drain the servers rather than dropping requests.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if cfg.Store == config.StoreMemory {
+		store, err := ingest.LoadMemoryStore(cfg.Fixtures, log)
+		if err != nil {
+			log.Sugar().Fatalf("loading fixtures: %v", err)
+		}
+		if err := app.RunInMemory(ctx, log, cfg, store); err != nil {
+			log.Sugar().Fatalf("running app: %v", err)
+		}
+		return
+	}
+
+	if err := app.Run(ctx, log, cfg); err != nil {
 		log.Sugar().Fatalf("running app: %v", err)
 	}
 }
@@ -1,12 +1,20 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"github.com/coreyvan/backend-takehome/internal/app"
+	"github.com/coreyvan/backend-takehome/internal/config"
+	"github.com/coreyvan/backend-takehome/internal/database"
 	"github.com/coreyvan/backend-takehome/internal/ingest"
+	"github.com/coreyvan/backend-takehome/internal/migrate"
 	"go.uber.org/zap"
-	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"io"
+	"net/http"
 	"os"
+	"strconv"
+	"time"
 )
 
 func main() {
@@ -21,17 +29,27 @@ func main() {
 }
 
 func run(log *zap.Logger) error {
-	command := os.Args[1]
+	cfg, args, err := config.Load("telegraph-cli", os.Args[1:])
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	if len(args) < 2 {
+		return fmt.Errorf("usage: telegraph-cli [flags] ingest|migrate|apikey|webhook-sink ...")
+	}
+
+	command := args[0]
 
 	switch command {
 	case "ingest":
-		dsn := "host=localhost user=candidate password=password123 dbname=telegraph port=5432 sslmode=disable"
-		db, err := gorm.Open(postgres.Open(dsn))
+		db, err := database.Open(cfg.DB)
 		if err != nil {
 			return fmt.Errorf("opening db: %w", err)
 		}
+		if err := requireCurrentSchema(db, log); err != nil {
+			return err
+		}
 		i := ingest.NewIngester(db, log)
-		kind := os.Args[2]
+		kind := args[1]
 		switch kind {
 		case "locations":
 			log.Sugar().Infof("ingesting locations...")
@@ -54,6 +72,9 @@ func run(log *zap.Logger) error {
 				return fmt.Errorf("processing events: %w", err)
 			}
 			log.Sugar().Infof("success... ingested %d rows", n)
+			if err := evaluateAlerts(db, log, cfg.Alerts); err != nil {
+				return err
+			}
 		case "waybills":
 			log.Sugar().Infof("ingesting waybills...")
 			n, err := i.ProcessWaybills("data/waybills.csv")
@@ -61,12 +82,156 @@ func run(log *zap.Logger) error {
 				return fmt.Errorf("processing waybills: %w", err)
 			}
 			log.Sugar().Infof("success... ingested %d rows", n)
+			if err := evaluateAlerts(db, log, cfg.Alerts); err != nil {
+				return err
+			}
 		default:
 			return fmt.Errorf("invalid kind %s", kind)
 		}
+	case "migrate":
+		db, err := database.Open(cfg.DB)
+		if err != nil {
+			return fmt.Errorf("opening db: %w", err)
+		}
+		m, err := migrate.New(db, log)
+		if err != nil {
+			return fmt.Errorf("loading migrations: %w", err)
+		}
+
+		ctx := context.Background()
+		action := args[1]
+		switch action {
+		case "up":
+			applied, err := m.Up(ctx)
+			if err != nil {
+				return err
+			}
+			log.Sugar().Infof("success... applied %d migrations", len(applied))
+		case "down":
+			if _, err := m.Down(ctx); err != nil {
+				return err
+			}
+		case "status":
+			statuses, err := m.Status(ctx)
+			if err != nil {
+				return err
+			}
+			for _, s := range statuses {
+				applied := "pending"
+				if s.AppliedAt != nil {
+					applied = "applied " + s.AppliedAt.Format(time.RFC3339)
+				}
+				fmt.Printf("%04d\t%s\t%s\n", s.Version, s.Name, applied)
+			}
+		default:
+			return fmt.Errorf("invalid migrate action %s", action)
+		}
+	case "apikey":
+		db, err := database.Open(cfg.DB)
+		if err != nil {
+			return fmt.Errorf("opening db: %w", err)
+		}
+
+		action := args[1]
+		switch action {
+		case "create":
+			// apikey create NAME CUSTOMER [CUSTOMER...], where a customer of *
+			// grants access to every customer.
+			if len(args) < 4 {
+				return fmt.Errorf("usage: apikey create NAME CUSTOMER [CUSTOMER...]")
+			}
+			key, k, err := app.CreateAPIKey(db, args[2], args[3:])
+			if err != nil {
+				return err
+			}
+			log.Sugar().Infof("created api key %d (%s) for %s", k.ID, k.Prefix, k.Customers)
+			fmt.Println(key)
+		case "revoke":
+			if len(args) < 3 {
+				return fmt.Errorf("usage: apikey revoke ID")
+			}
+			id, err := strconv.ParseUint(args[2], 10, 64)
+			if err != nil {
+				return fmt.Errorf("parsing api key id: %w", err)
+			}
+			if err := app.RevokeAPIKey(db, uint(id)); err != nil {
+				return err
+			}
+			log.Sugar().Infof("revoked api key %d", id)
+		case "list":
+			var keys []app.APIKey
+			if err := db.Order("id").Find(&keys).Error; err != nil {
+				return fmt.Errorf("finding api keys: %w", err)
+			}
+			for _, k := range keys {
+				status := "active"
+				if k.RevokedAt != nil {
+					status = "revoked " + k.RevokedAt.Format(time.RFC3339)
+				}
+				fmt.Printf("%d\t%s\t%s\t%s\t%s\n", k.ID, k.Prefix, k.Name, k.Customers, status)
+			}
+		default:
+			return fmt.Errorf("invalid apikey action %s", action)
+		}
+	case "webhook-sink":
+		// A local stand-in for a subscriber: logs each delivery and, given the
+		// subscription secret, checks its signature.
+		port := args[1]
+		var secret string
+		if len(args) > 2 {
+			secret = args[2]
+		}
+
+		http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				log.Sugar().Errorf("reading delivery: %v", err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			if secret != "" && !app.VerifySignature(secret, r.Header.Get(app.TimestampHeader), r.Header.Get(app.SignatureHeader), body) {
+				log.Sugar().Warnf("rejecting delivery %s: bad signature", r.Header.Get(app.DeliveryHeader))
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			log.Sugar().Infof("received delivery %s: %s", r.Header.Get(app.DeliveryHeader), body)
+			w.WriteHeader(http.StatusNoContent)
+		})
+
+		log.Sugar().Infof("webhook sink listening on port %s...", port)
+		return http.ListenAndServe(fmt.Sprintf(":%s", port), nil)
 	default:
 		return fmt.Errorf("invalid command %s", command)
 	}
 
 	return nil
 }
+
+// evaluateAlerts re-runs the alert rules against freshly ingested data.
+func evaluateAlerts(db *gorm.DB, log *zap.Logger, rulesPath string) error {
+	cfg, err := app.LoadAlertConfig(rulesPath)
+	if err != nil {
+		return fmt.Errorf("loading alert rules: %w", err)
+	}
+
+	summary, err := app.NewAlertEngine(db, log, cfg).Evaluate(context.Background(), time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("evaluating alerts: %w", err)
+	}
+	log.Sugar().Infof("alerts: %d opened, %d updated, %d resolved", summary.Opened, summary.Updated, summary.Resolved)
+
+	return nil
+}
+
+func requireCurrentSchema(db *gorm.DB, log *zap.Logger) error {
+	m, err := migrate.New(db, log)
+	if err != nil {
+		return fmt.Errorf("loading migrations: %w", err)
+	}
+	if err := m.RequireCurrent(context.Background()); err != nil {
+		return fmt.Errorf("checking schema: %w", err)
+	}
+	return nil
+}
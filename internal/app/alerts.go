@@ -0,0 +1,466 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v2"
+	"gorm.io/gorm"
+	"net/http"
+	"os"
+	"sort"
+	"time"
+)
+
+const (
+	AlertOpen         = "open"
+	AlertAcknowledged = "acknowledged"
+	AlertResolved     = "resolved"
+)
+
+// Alert rule types.
+const (
+	RuleStalled        = "stalled"
+	RuleDwell          = "dwell"
+	RuleLoadStatusFlip = "load_status_flip"
+	RuleOffRoute       = "off_route"
+)
+
+// AlertRule configures one check. Threshold applies to stalled and dwell
+// rules; EventCodes limits which sightings off_route considers.
+type AlertRule struct {
+	Name       string        `yaml:"name" json:"name"`
+	Type       string        `yaml:"type" json:"type"`
+	Severity   string        `yaml:"severity" json:"severity"`
+	Threshold  time.Duration `yaml:"threshold" json:"threshold"`
+	EventCodes []string      `yaml:"event_codes" json:"event_codes,omitempty"`
+}
+
+// AlertConfig is the rule set evaluated on ingest and every Interval by the
+// API. A waybill whose latest sighting has one of CompleteEventCodes is done
+// and no longer considered stalled or dwelling.
+type AlertConfig struct {
+	Interval           time.Duration `yaml:"interval"`
+	CompleteEventCodes []string      `yaml:"complete_event_codes"`
+	Rules              []AlertRule   `yaml:"rules"`
+}
+
+// DefaultAlertConfig is used when no rules file is present.
+var DefaultAlertConfig = AlertConfig{
+	Interval:           15 * time.Minute,
+	CompleteEventCodes: []string{"6007"},
+	Rules: []AlertRule{
+		{Name: "stalled", Type: RuleStalled, Severity: "warning", Threshold: 48 * time.Hour},
+		{Name: "long-dwell", Type: RuleDwell, Severity: "warning", Threshold: 72 * time.Hour},
+		{Name: "load-status-flip", Type: RuleLoadStatusFlip, Severity: "critical"},
+		{Name: "off-route", Type: RuleOffRoute, Severity: "critical", EventCodes: []string{"6016"}},
+	},
+}
+
+// LoadAlertConfig reads rules from a YAML file, falling back to
+// DefaultAlertConfig when the file doesn't exist.
+func LoadAlertConfig(path string) (AlertConfig, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return DefaultAlertConfig, nil
+	}
+	if err != nil {
+		return AlertConfig{}, fmt.Errorf("reading alert rules: %w", err)
+	}
+
+	cfg := AlertConfig{Interval: DefaultAlertConfig.Interval}
+	if err := yaml.UnmarshalStrict(b, &cfg); err != nil {
+		return AlertConfig{}, fmt.Errorf("parsing alert rules: %w", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return AlertConfig{}, err
+	}
+
+	return cfg, nil
+}
+
+func (cfg AlertConfig) Validate() error {
+	if cfg.Interval <= 0 {
+		return errors.New("alert interval must be positive")
+	}
+
+	names := make(map[string]bool)
+	for _, r := range cfg.Rules {
+		if r.Name == "" {
+			return errors.New("alert rule missing name")
+		}
+		if names[r.Name] {
+			return fmt.Errorf("duplicate alert rule %s", r.Name)
+		}
+		names[r.Name] = true
+
+		switch r.Type {
+		case RuleStalled, RuleDwell:
+			if r.Threshold <= 0 {
+				return fmt.Errorf("alert rule %s needs a positive threshold", r.Name)
+			}
+		case RuleLoadStatusFlip, RuleOffRoute:
+		default:
+			return fmt.Errorf("alert rule %s has unknown type %s", r.Name, r.Type)
+		}
+	}
+
+	return nil
+}
+
+// Alert is a rule firing for a waybill. Key identifies the condition so
+// repeated evaluations update the same alert, and a condition that clears
+// resolves it. A resolved alert stays resolved, even if its condition still
+// holds; a new stall or flip fires under a new key.
+type Alert struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	Key            string     `json:"key"`
+	Rule           string     `json:"rule"`
+	Type           string     `json:"type"`
+	Severity       string     `json:"severity"`
+	Status         string     `json:"status"`
+	WaybillID      string     `json:"waybill_id"`
+	EquipmentID    string     `json:"equipment_id"`
+	LocationID     string     `json:"location_id,omitempty"`
+	EventID        string     `json:"event_id,omitempty"`
+	Message        string     `json:"message"`
+	OpenedAt       time.Time  `json:"opened_at"`
+	LastSeenAt     time.Time  `json:"last_seen_at"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at"`
+	ResolvedAt     *time.Time `json:"resolved_at"`
+}
+
+// AlertEngine evaluates alert rules against the current waybills and events.
+type AlertEngine struct {
+	db  *gorm.DB
+	log *zap.Logger
+	cfg AlertConfig
+}
+
+func NewAlertEngine(db *gorm.DB, log *zap.Logger, cfg AlertConfig) *AlertEngine {
+	return &AlertEngine{db: db, log: log, cfg: cfg}
+}
+
+// Run evaluates rules every configured interval until ctx is cancelled.
+func (a *AlertEngine) Run(ctx context.Context) {
+	ticker := time.NewTicker(a.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := a.Evaluate(ctx, time.Now().UTC()); err != nil {
+			a.log.Sugar().Errorf("evaluating alerts: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// AlertSummary counts what an evaluation changed.
+type AlertSummary struct {
+	Opened   int
+	Updated  int
+	Resolved int
+}
+
+// Evaluate runs every rule as of now, opening alerts for new conditions and
+// resolving active alerts whose condition has cleared. Conditions whose alert
+// was resolved, by hand or otherwise, aren't opened again.
+func (a *AlertEngine) Evaluate(ctx context.Context, now time.Time) (AlertSummary, error) {
+	db := a.db.WithContext(ctx)
+
+	var waybills []Waybill
+	if err := db.Find(&waybills).Error; err != nil {
+		return AlertSummary{}, fmt.Errorf("finding waybills: %w", err)
+	}
+
+	var events []Event
+	if err := db.Order("sighting_date, id").Find(&events).Error; err != nil {
+		return AlertSummary{}, fmt.Errorf("finding events: %w", err)
+	}
+
+	byWaybill := make(map[string][]Event)
+	for _, e := range events {
+		byWaybill[e.WaybillID] = append(byWaybill[e.WaybillID], e)
+	}
+
+	firing := make(map[string]Alert)
+	for _, w := range waybills {
+		for _, alert := range a.check(w, byWaybill[w.ID], now) {
+			firing[alert.Key] = alert
+		}
+	}
+
+	var summary AlertSummary
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var active []Alert
+		if err := tx.Where("status IN ?", []string{AlertOpen, AlertAcknowledged}).Find(&active).Error; err != nil {
+			return fmt.Errorf("finding active alerts: %w", err)
+		}
+
+		for _, existing := range active {
+			alert, ok := firing[existing.Key]
+			if !ok {
+				if err := tx.Model(&existing).Updates(map[string]interface{}{"status": AlertResolved, "resolved_at": now}).Error; err != nil {
+					return fmt.Errorf("resolving alert %d: %w", existing.ID, err)
+				}
+				summary.Resolved++
+				continue
+			}
+
+			delete(firing, existing.Key)
+			if err := tx.Model(&existing).Updates(map[string]interface{}{"message": alert.Message, "last_seen_at": now}).Error; err != nil {
+				return fmt.Errorf("updating alert %d: %w", existing.ID, err)
+			}
+			summary.Updated++
+		}
+
+		var resolved []string
+		if err := tx.Model(&Alert{}).Where("status = ?", AlertResolved).Distinct().Pluck("key", &resolved).Error; err != nil {
+			return fmt.Errorf("finding resolved alerts: %w", err)
+		}
+		for _, key := range resolved {
+			delete(firing, key)
+		}
+
+		// Opening in key order keeps alert IDs stable across runs.
+		keys := make([]string, 0, len(firing))
+		for key := range firing {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			alert := firing[key]
+			alert.Status = AlertOpen
+			alert.OpenedAt = now
+			alert.LastSeenAt = now
+			if err := tx.Create(&alert).Error; err != nil {
+				return fmt.Errorf("opening alert %s: %w", alert.Key, err)
+			}
+			summary.Opened++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return AlertSummary{}, err
+	}
+
+	return summary, nil
+}
+
+// check returns the alerts firing for one waybill. Events must be in sighting
+// order.
+func (a *AlertEngine) check(w Waybill, events []Event, now time.Time) []Alert {
+	if len(events) == 0 {
+		return nil
+	}
+
+	last := events[len(events)-1]
+	complete := contains(a.cfg.CompleteEventCodes, last.SightingEventCode)
+
+	var alerts []Alert
+	for _, r := range a.cfg.Rules {
+		newAlert := func(key, message string) Alert {
+			return Alert{
+				Key:         fmt.Sprintf("%s:%s:%s", r.Name, w.ID, key),
+				Rule:        r.Name,
+				Type:        r.Type,
+				Severity:    r.Severity,
+				WaybillID:   w.ID,
+				EquipmentID: w.EquipmentID,
+				Message:     message,
+			}
+		}
+
+		switch r.Type {
+		case RuleStalled:
+			if complete {
+				continue
+			}
+			if since := now.Sub(last.SightingDate); since > r.Threshold {
+				alert := newAlert(last.ID, fmt.Sprintf("no sighting of %s for %s", w.EquipmentID, since.Round(time.Hour)))
+				alert.LocationID = last.LocationID
+				alert.EventID = last.ID
+				alerts = append(alerts, alert)
+			}
+
+		case RuleDwell:
+			for k := 0; k < len(events); {
+				arrival := events[k]
+				next := k + 1
+				for next < len(events) && events[next].LocationID == arrival.LocationID {
+					next++
+				}
+
+				var dwell time.Duration
+				switch {
+				case next < len(events):
+					dwell = events[next].SightingDate.Sub(arrival.SightingDate)
+				case !complete:
+					dwell = now.Sub(arrival.SightingDate)
+				}
+
+				if dwell > r.Threshold {
+					alert := newAlert(arrival.ID, fmt.Sprintf("%s dwelled %s at location %s", w.EquipmentID, dwell.Round(time.Hour), arrival.LocationID))
+					alert.LocationID = arrival.LocationID
+					alert.EventID = arrival.ID
+					alerts = append(alerts, alert)
+				}
+				k = next
+			}
+
+		case RuleLoadStatusFlip:
+			for k := 1; k < len(events); k++ {
+				prev, cur := events[k-1], events[k]
+				if prev.LoadEmptyStatus == "" || cur.LoadEmptyStatus == "" || prev.LoadEmptyStatus == cur.LoadEmptyStatus {
+					continue
+				}
+				// Cars are loaded at the origin and unloaded at the destination.
+				if cur.LocationID == w.OriginID || cur.LocationID == w.DestinationID {
+					continue
+				}
+				alert := newAlert(cur.ID, fmt.Sprintf("%s changed from %s to %s mid-trip", w.EquipmentID, prev.LoadEmptyStatus, cur.LoadEmptyStatus))
+				alert.LocationID = cur.LocationID
+				alert.EventID = cur.ID
+				alerts = append(alerts, alert)
+			}
+
+		case RuleOffRoute:
+			route, err := w.DecodeRoutes()
+			if err != nil || len(route) == 0 {
+				continue
+			}
+			planned := make([]string, 0, len(route))
+			for _, p := range route {
+				planned = append(planned, p.Scac)
+			}
+
+			for _, e := range events {
+				if len(r.EventCodes) > 0 && !contains(r.EventCodes, e.SightingEventCode) {
+					continue
+				}
+				if e.ReportingRailroadSCAC == "" || contains(planned, e.ReportingRailroadSCAC) {
+					continue
+				}
+				alert := newAlert(e.ReportingRailroadSCAC, fmt.Sprintf("%s reported by %s, which is not on the planned route", w.EquipmentID, e.ReportingRailroadSCAC))
+				alert.LocationID = e.LocationID
+				alert.EventID = e.ID
+				alerts = append(alerts, alert)
+			}
+		}
+	}
+
+	return alerts
+}
+
+func (h *HTTP) Alerts() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		where := h.db.Model(&Alert{}).Scopes(h.scopeWaybillIDs(c))
+		if status := c.Query("status"); status != "" {
+			if !contains([]string{AlertOpen, AlertAcknowledged, AlertResolved}, status) {
+				h.invalid(c, FieldError{Name: "status", In: "query", Reason: "must be open, acknowledged or resolved"})
+				return
+			}
+			where = where.Where("status = ?", status)
+		}
+		if rule := c.Query("rule"); rule != "" {
+			where = where.Where("rule = ?", rule)
+		}
+		if waybillID := c.Query("waybill_id"); waybillID != "" {
+			where = where.Where("waybill_id = ?", waybillID)
+		}
+
+		var alerts []Alert
+		result := where.Order("opened_at DESC, id DESC").Find(&alerts)
+		if result.Error != nil {
+			h.internalError(c, fmt.Errorf("finding alerts: %w", result.Error))
+			return
+		}
+		c.JSON(http.StatusOK, alerts)
+	}
+}
+
+func (h *HTTP) AlertByID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		alert, ok := h.findAlert(c)
+		if !ok {
+			return
+		}
+		c.JSON(http.StatusOK, alert)
+	}
+}
+
+func (h *HTTP) AcknowledgeAlert() gin.HandlerFunc {
+	return h.transitionAlert(AlertAcknowledged, "acknowledged_at", AlertOpen)
+}
+
+func (h *HTTP) ResolveAlert() gin.HandlerFunc {
+	return h.transitionAlert(AlertResolved, "resolved_at", AlertOpen, AlertAcknowledged)
+}
+
+// transitionAlert moves an alert to status if it's currently in one of from.
+func (h *HTTP) transitionAlert(status, stampColumn string, from ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		alert, ok := h.findAlert(c)
+		if !ok {
+			return
+		}
+
+		if !contains(from, alert.Status) {
+			h.problem(c, http.StatusConflict, CodeAlertStateConflict, fmt.Sprintf("Alert %d is %s.", alert.ID, alert.Status))
+			return
+		}
+
+		result := h.db.Model(&alert).Updates(map[string]interface{}{"status": status, stampColumn: time.Now().UTC()})
+		if result.Error != nil {
+			h.internalError(c, fmt.Errorf("updating alert: %w", result.Error))
+			return
+		}
+
+		if result := h.db.First(&alert, alert.ID); result.Error != nil {
+			h.internalError(c, fmt.Errorf("reloading alert: %w", result.Error))
+			return
+		}
+
+		c.JSON(http.StatusOK, alert)
+	}
+}
+
+func (h *HTTP) findAlert(c *gin.Context) (Alert, bool) {
+	var errs []FieldError
+	id := pathID(c, &errs)
+	if len(errs) > 0 {
+		h.invalid(c, errs...)
+		return Alert{}, false
+	}
+
+	var alert Alert
+	result := h.db.Scopes(h.scopeWaybillIDs(c)).Where("id = ?", id).First(&alert)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			h.problem(c, http.StatusNotFound, CodeAlertNotFound, fmt.Sprintf("Alert %d was not found.", id))
+			return Alert{}, false
+		}
+		h.internalError(c, fmt.Errorf("finding alert by id: %w", result.Error))
+		return Alert{}, false
+	}
+
+	return alert, true
+}
+
+func contains(values []string, v string) bool {
+	for _, s := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
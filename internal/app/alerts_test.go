@@ -0,0 +1,76 @@
+package app_test
+
+import (
+	"context"
+	"github.com/coreyvan/backend-takehome/internal/app"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+var flipRules = app.AlertConfig{
+	Interval: time.Minute,
+	Rules:    []app.AlertRule{{Name: "flip", Type: app.RuleLoadStatusFlip, Severity: "critical"}},
+}
+
+// withFlips adds sightings of waybill 7, which runs from location 6 to 10 and
+// is otherwise always empty, that load it at its origin, unload it on the way
+// and load it again at its destination.
+func withFlips(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db := newTestDB(t)
+	for k, e := range []struct {
+		id, location, status string
+	}{
+		{"90701", "6", "L"},
+		{"90702", "450", "E"},
+		{"90703", "10", "L"},
+	} {
+		at := time.Date(2021, 8, 23+k, 0, 0, 0, 0, time.UTC)
+		event := app.Event{ID: e.id, EquipmentID: "PMRX346210", SightingDate: at, PostingDate: at, LoadEmptyStatus: e.status, LocationID: e.location, WaybillID: "7"}
+		if err := db.Create(&event).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
+
+// flipAlerts evaluates flipRules and returns the flip alerts.
+func flipAlerts(t *testing.T, db *gorm.DB) []app.Alert {
+	t.Helper()
+
+	if _, err := app.NewAlertEngine(db, zap.NewNop(), flipRules).Evaluate(context.Background(), evaluatedAt); err != nil {
+		t.Fatal(err)
+	}
+	var alerts []app.Alert
+	if err := db.Where("rule = ?", "flip").Find(&alerts).Error; err != nil {
+		t.Fatal(err)
+	}
+	return alerts
+}
+
+func TestLoadStatusFlipIgnoresOriginAndDestination(t *testing.T) {
+	alerts := flipAlerts(t, withFlips(t))
+	if len(alerts) != 1 || alerts[0].Key != "flip:7:90702" {
+		t.Errorf("flip alerts = %v, want only the unload at location 450", alerts)
+	}
+}
+
+func TestEvaluateKeepsResolvedAlertsResolved(t *testing.T) {
+	db := withFlips(t)
+	alerts := flipAlerts(t, db)
+	if len(alerts) != 1 {
+		t.Fatalf("flip alerts = %v, want the unload at location 450", alerts)
+	}
+	alert := alerts[0]
+	if err := db.Model(&alert).Updates(map[string]interface{}{"status": app.AlertResolved, "resolved_at": evaluatedAt}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	alerts = flipAlerts(t, db)
+	if len(alerts) != 1 || alerts[0].ID != alert.ID || alerts[0].Status != app.AlertResolved {
+		t.Errorf("after resolving alert %d by hand, flip alerts = %v", alert.ID, alerts)
+	}
+}
@@ -0,0 +1,185 @@
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	apiKeyPrefix = "tg_"
+
+	// AllCustomers binds a key to every customer, for internal tools.
+	AllCustomers = "*"
+
+	customersKey = "customers"
+
+	// touchInterval is how stale a key's last_used_at gets before a request
+	// updates it.
+	touchInterval = time.Minute
+)
+
+// APIKey grants access to the data of the customers it's bound to. Only a
+// SHA-256 hash of the key is stored; Prefix identifies it in listings.
+type APIKey struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Hash       string     `json:"-"`
+	Customers  string     `json:"customers"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+}
+
+// CreateAPIKey stores a new key bound to customers and returns the plaintext
+// key, which can't be recovered afterwards.
+func CreateAPIKey(db *gorm.DB, name string, customers []string) (string, APIKey, error) {
+	key, k, err := newAPIKey(name, customers)
+	if err != nil {
+		return "", APIKey{}, err
+	}
+	if err := db.Create(&k).Error; err != nil {
+		return "", APIKey{}, fmt.Errorf("creating api key: %w", err)
+	}
+
+	return key, k, nil
+}
+
+// newAPIKey generates a key bound to customers, returning the plaintext key
+// and the unsaved record.
+func newAPIKey(name string, customers []string) (string, APIKey, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", APIKey{}, fmt.Errorf("generating key: %w", err)
+	}
+	key := apiKeyPrefix + hex.EncodeToString(b)
+
+	k, err := apiKeyRecord(name, key, customers)
+	return key, k, err
+}
+
+// apiKeyRecord returns the unsaved record of a plaintext key bound to
+// customers.
+func apiKeyRecord(name, key string, customers []string) (APIKey, error) {
+	if !strings.HasPrefix(key, apiKeyPrefix) || len(key) < len(apiKeyPrefix)+16 {
+		return APIKey{}, fmt.Errorf("api key must be %s followed by at least 16 characters", apiKeyPrefix)
+	}
+	if len(customers) == 0 {
+		return APIKey{}, errors.New("api key needs at least one customer")
+	}
+	for _, c := range customers {
+		if c == "" || strings.Contains(c, ",") {
+			return APIKey{}, fmt.Errorf("invalid customer %q", c)
+		}
+	}
+
+	return APIKey{
+		Name:      name,
+		Prefix:    key[:len(apiKeyPrefix)+8],
+		Hash:      hashAPIKey(key),
+		Customers: strings.Join(customers, ","),
+	}, nil
+}
+
+// RevokeAPIKey stops a key from authenticating.
+func RevokeAPIKey(db *gorm.DB, id uint) error {
+	result := db.Model(&APIKey{}).Where("id = ? AND revoked_at IS NULL", id).Update("revoked_at", time.Now().UTC())
+	if result.Error != nil {
+		return fmt.Errorf("revoking api key: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("no active api key with id %d", id)
+	}
+	return nil
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// authenticate requires a valid API key in the X-API-Key header or as a
+// bearer token, and records the customers it's bound to for scoping.
+func (h *HTTP) authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader("X-API-Key")
+		if key == "" {
+			key = strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		}
+		if key == "" || !strings.HasPrefix(key, apiKeyPrefix) {
+			h.problem(c, http.StatusUnauthorized, CodeAPIKeyRequired, "Send an API key in the X-API-Key header or as a bearer token.")
+			return
+		}
+
+		k, err := h.activeAPIKey(c.Request.Context(), key)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				h.problem(c, http.StatusUnauthorized, CodeAPIKeyInvalid, "The API key is unknown or has been revoked.")
+				return
+			}
+			h.internalError(c, err)
+			return
+		}
+
+		c.Set(customersKey, strings.Split(k.Customers, ","))
+		c.Next()
+	}
+}
+
+// activeAPIKey finds the unrevoked key a caller sent and records its use, at
+// most once per touchInterval so reads don't each cost a write.
+func (h *HTTP) activeAPIKey(ctx context.Context, key string) (APIKey, error) {
+	k, err := h.keys.ActiveAPIKey(ctx, hashAPIKey(key))
+	if err != nil {
+		return APIKey{}, fmt.Errorf("finding api key: %w", err)
+	}
+
+	now := time.Now().UTC()
+	if k.LastUsedAt == nil || now.Sub(*k.LastUsedAt) >= touchInterval {
+		if err := h.keys.TouchAPIKey(ctx, k.ID, now); err != nil {
+			h.log.Sugar().Warnf("recording api key use: %v", err)
+		}
+	}
+	return k, nil
+}
+
+// customerScope returns the customers the request may see.
+func customerScope(c *gin.Context) Scope {
+	return scopeFor(c.GetStringSlice(customersKey))
+}
+
+// scopeFor returns the scope of a key bound to customers.
+func scopeFor(customers []string) Scope {
+	for _, customer := range customers {
+		if customer == AllCustomers {
+			return Scope{}
+		}
+	}
+	return Scope{Customers: customers, Restricted: true}
+}
+
+// canSee reports whether the request may see a customer's data.
+func canSee(c *gin.Context, customer string) bool {
+	return customerScope(c).Allows(customer)
+}
+
+// scopeWaybillIDs limits queries with a waybill_id column to the request's
+// waybills.
+func (h *HTTP) scopeWaybillIDs(c *gin.Context) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		scope := customerScope(c)
+		if !scope.Restricted {
+			return db
+		}
+		return db.Where("waybill_id IN (?)", scopedWaybillIDs(h.db, scope))
+	}
+}
@@ -0,0 +1,49 @@
+package app_test
+
+import (
+	"github.com/coreyvan/backend-takehome/internal/app"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// TestAPIKeyLastUsed checks a key's use is recorded, but not on every
+// request.
+func TestAPIKeyLastUsed(t *testing.T) {
+	db := newTestDB(t)
+	srv, keys, _ := serveTestAPI(t, db)
+
+	lastUsed := func() time.Time {
+		t.Helper()
+		var k app.APIKey
+		if err := db.Where("name = ?", "admin").First(&k).Error; err != nil {
+			t.Fatal(err)
+		}
+		if k.LastUsedAt == nil {
+			return time.Time{}
+		}
+		return *k.LastUsedAt
+	}
+
+	if res := get(t, srv.URL+"/waybills/7", keys["admin"], ""); res.StatusCode != http.StatusOK {
+		t.Fatalf("waybill = %d", res.StatusCode)
+	}
+	first := lastUsed()
+	if first.IsZero() {
+		t.Fatal("use wasn't recorded")
+	}
+
+	get(t, srv.URL+"/waybills/7", keys["admin"], "")
+	if got := lastUsed(); !got.Equal(first) {
+		t.Errorf("last used moved from %s to %s within a minute", first, got)
+	}
+
+	stale := time.Now().UTC().Add(-2 * time.Minute)
+	if err := db.Model(&app.APIKey{}).Where("name = ?", "admin").Update("last_used_at", stale).Error; err != nil {
+		t.Fatal(err)
+	}
+	get(t, srv.URL+"/waybills/7", keys["admin"], "")
+	if got := lastUsed(); !got.After(stale) {
+		t.Errorf("stale last used %s wasn't updated", got)
+	}
+}
@@ -1,22 +1,144 @@
 package app
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/coreyvan/backend-takehome/internal/config"
+	"github.com/coreyvan/backend-takehome/internal/database"
+	"github.com/coreyvan/backend-takehome/internal/migrate"
 	"go.uber.org/zap"
-	"gorm.io/driver/postgres"
-	"gorm.io/gorm"
-	"os"
+	"sync"
 )
 
-func Run(log *zap.Logger) error {
-	dsn := "host=localhost user=candidate password=password123 dbname=telegraph port=5432 sslmode=disable"
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+// Run serves the API from the database until ctx is done, then shuts it down
+// gracefully.
+func Run(ctx context.Context, log *zap.Logger, cfg config.Config) error {
+	log.Sugar().Infow("loaded config", "config", cfg.Redacted())
+
+	db, err := database.OpenWhenReady(ctx, cfg.DB, log)
+	if err != nil {
+		return fmt.Errorf("opening ORM: %w", err)
+	}
+
+	migrator, err := migrate.New(db, log)
+	if err != nil {
+		return fmt.Errorf("loading migrations: %w", err)
+	}
+	if err := migrator.RequireCurrent(ctx); err != nil {
+		return fmt.Errorf("checking schema: %w", err)
+	}
+
+	alerts, err := LoadAlertConfig(cfg.Alerts)
+	if err != nil {
+		return fmt.Errorf("loading alert rules: %w", err)
+	}
+
+	demurrage, err := LoadDemurrageConfig(cfg.Demurrage)
 	if err != nil {
-		log.Sugar().Fatalf("opening ORM: %v", err)
+		return fmt.Errorf("loading demurrage tariffs: %w", err)
 	}
 
-	port := os.Args[1]
-	srv := NewHTTP(log, port, db)
+	stores := NewGormStore(db).Stores()
+	if cfg.Cache.Size > 0 {
+		cached := NewCachedStore(stores, NewLRUCache(cfg.Cache.Size, cfg.Cache.TTL))
+		cached.RevisionTTL = cfg.Cache.RevisionTTL
+		stores = cached.Stores()
+	}
+
+	srv := NewHTTP(log, cfg.HTTP, stores, db)
+	srv.demurrage = demurrage
+	srv.allowPrivateTargets = cfg.Webhooks.AllowPrivateTargets
+
+	webhooks := NewDeliveryWorker(db, log, NewWebhookClient(cfg.Webhooks.Timeout, cfg.Webhooks.AllowPrivateTargets))
+	webhooks.Interval = cfg.Webhooks.Interval
+	webhooks.MaxAttempts = cfg.Webhooks.MaxAttempts
+
+	return serve(ctx, log, cfg, srv, webhooks.Run, NewAlertEngine(db, log, alerts).Run)
+}
+
+// RunInMemory serves the API from store without a database until ctx is
+// done. Since there are no stored API keys, it accepts the unrestricted key
+// from the config, or mints one and prints it to stdout, out of the logs.
+func RunInMemory(ctx context.Context, log *zap.Logger, cfg config.Config, store *MemoryStore) error {
+	log.Sugar().Infow("loaded config", "config", cfg.Redacted())
+
+	demurrage, err := LoadDemurrageConfig(cfg.Demurrage)
+	if err != nil {
+		return fmt.Errorf("loading demurrage tariffs: %w", err)
+	}
+
+	if cfg.MemoryAPIKey != "" {
+		if _, err := store.AddAPIKey("local", cfg.MemoryAPIKey, []string{AllCustomers}); err != nil {
+			return fmt.Errorf("adding memory api key: %w", err)
+		}
+	} else {
+		key, _, err := store.CreateAPIKey("local", []string{AllCustomers})
+		if err != nil {
+			return fmt.Errorf("creating api key: %w", err)
+		}
+		fmt.Printf("API key: %s\n", key)
+	}
+	log.Sugar().Infof("serving %s from memory; subscriptions and alerts are disabled", cfg.Fixtures)
+
+	srv := NewHTTP(log, cfg.HTTP, store.Stores(), nil)
+	srv.demurrage = demurrage
+
+	return serve(ctx, log, cfg, srv)
+}
+
+// serve runs the HTTP API, the gRPC service and the background workers until
+// ctx is done or either server fails, then stops the workers and gives
+// everything up to the shutdown timeout to finish the work in flight.
+func serve(ctx context.Context, log *zap.Logger, cfg config.Config, srv *HTTP, workers ...func(context.Context)) error {
+	work, stopWork := context.WithCancel(context.Background())
+	defer stopWork()
+	var wg sync.WaitGroup
+	for _, run := range workers {
+		wg.Add(1)
+		go func(run func(context.Context)) {
+			defer wg.Done()
+			run(work)
+		}(run)
+	}
+
+	grpcSrv := NewGRPC(srv, cfg.GRPC.Port)
+	errs := make(chan error, 2)
+	go func() { errs <- srv.Listen() }()
+	go func() { errs <- grpcSrv.Listen() }()
+
+	log.Sugar().Infof("🚀 server listening on port %s, grpc on port %s...", cfg.HTTP.Port, cfg.GRPC.Port)
+
+	var err error
+	select {
+	case err = <-errs:
+	case <-ctx.Done():
+	}
+	log.Sugar().Infof("shutting down, waiting up to %s for requests in flight", cfg.HTTP.ShutdownTimeout)
+
+	shutdown, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
+	defer cancel()
+	stopWork()
+	// The HTTP server goes first, since shutting it down ends the gRPC
+	// streams too.
+	if shutdownErr := srv.Shutdown(shutdown); shutdownErr != nil && err == nil {
+		err = fmt.Errorf("shutting down http: %w", shutdownErr)
+	}
+	grpcSrv.Shutdown(shutdown)
+
+	stopped := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-shutdown.Done():
+		if err == nil {
+			err = errors.New("background workers didn't stop within the shutdown timeout")
+		}
+	}
 
-	log.Sugar().Infof("🚀 server listening on port %s...", port)
-	return srv.Listen()
+	log.Sugar().Info("server stopped")
+	return err
 }
@@ -0,0 +1,140 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Datasets ingestion loads, each replacing the table of the same name.
+const (
+	DatasetLocations = "locations"
+	DatasetEquipment = "equipment"
+	DatasetWaybills  = "waybills"
+	DatasetEvents    = "events"
+)
+
+// IngestRun records a load of a dataset. The latest run of the datasets a
+// response is built from validates cached copies of it.
+type IngestRun struct {
+	ID         uint `gorm:"primaryKey"`
+	Dataset    string
+	RowCount   int
+	IngestedAt time.Time
+}
+
+// RecordIngestRun records that rows were loaded into a dataset. Call it in the
+// transaction replacing the rows, so cached responses aren't revalidated
+// against data that was rolled back.
+func RecordIngestRun(db *gorm.DB, dataset string, rows int) error {
+	run := IngestRun{Dataset: dataset, RowCount: rows, IngestedAt: time.Now().UTC()}
+	if err := db.Create(&run).Error; err != nil {
+		return fmt.Errorf("recording ingest run: %w", err)
+	}
+	return nil
+}
+
+// cachePolicy is how clients may cache a route's responses.
+type cachePolicy struct {
+	// Control is the Cache-Control header.
+	Control string
+	// Datasets are the ingested data responses are built from. Responses
+	// carry an ETag and Last-Modified from their latest ingest run, and none
+	// when there are no datasets.
+	Datasets []string
+}
+
+var (
+	// Locations are reference data, rarely reloaded, so they're reused for
+	// an hour without asking.
+	cacheLocations = cachePolicy{Control: "private, max-age=3600", Datasets: []string{DatasetLocations}}
+	cacheEquipment = cachePolicy{Control: "private, no-cache", Datasets: []string{DatasetEquipment}}
+	// Events are scoped by the equipment sighted.
+	cacheEvents = cachePolicy{Control: "private, no-cache", Datasets: []string{DatasetEquipment, DatasetEvents}}
+	// Waybills are scoped by equipment and joined to events and locations.
+	cacheWaybills = cachePolicy{Control: "private, no-cache", Datasets: []string{DatasetLocations, DatasetEquipment, DatasetWaybills, DatasetEvents}}
+	// cacheNow is for responses as of the current time by default, which
+	// change without an ingest run.
+	cacheNow = cachePolicy{Control: "private, no-cache"}
+	// cacheNone is for data changed through the API, like subscriptions and
+	// alerts.
+	cacheNone = cachePolicy{Control: "no-store"}
+)
+
+// cacheVary lists the request headers responses differ by besides the URL:
+// the representation asked for and the key whose customers scope the data.
+const cacheVary = "Accept, " + VersionHeader + ", X-API-Key, Authorization"
+
+// cached sets a route's Cache-Control and validators, and responds 304 Not
+// Modified when the request's If-None-Match or If-Modified-Since shows the
+// client's copy is current.
+func (h *HTTP) cached(p cachePolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", p.Control)
+		c.Header("Vary", cacheVary)
+		if len(p.Datasets) == 0 {
+			return
+		}
+
+		rev, err := h.revisions.Revision(c.Request.Context(), p.Datasets...)
+		if err != nil {
+			h.internalError(c, err)
+			return
+		}
+		if rev.ID == 0 {
+			return
+		}
+
+		etag := entityTag(c, rev)
+		c.Header("ETag", etag)
+		c.Header("Last-Modified", rev.ModifiedAt.UTC().Format(http.TimeFormat))
+		if notModified(c.Request, etag, rev.ModifiedAt) {
+			c.AbortWithStatus(http.StatusNotModified)
+		}
+	}
+}
+
+// entityTag identifies the response to a request at a revision. Besides the
+// URL, it covers what cacheVary lists: the version, the Accept header and the
+// customers the key sees, rather than the key itself.
+func entityTag(c *gin.Context, rev Revision) string {
+	sum := sha256.New()
+	for _, part := range []string{
+		strconv.FormatUint(uint64(rev.ID), 10),
+		c.Request.URL.Path,
+		c.Request.URL.RawQuery,
+		strconv.Itoa(requestVersion(c)),
+		c.GetHeader("Accept"),
+		scopeKey(customerScope(c)),
+	} {
+		sum.Write([]byte(part))
+		sum.Write([]byte{0})
+	}
+	return `"` + hex.EncodeToString(sum.Sum(nil)[:12]) + `"`
+}
+
+// notModified evaluates a GET's preconditions as RFC 9110 does: If-None-Match
+// compares ETags weakly, and If-Modified-Since only counts without it.
+func notModified(r *http.Request, etag string, modifiedAt time.Time) bool {
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		for _, tag := range strings.Split(match, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	return !modifiedAt.Truncate(time.Second).After(since)
+}
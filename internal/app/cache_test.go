@@ -0,0 +1,129 @@
+package app_test
+
+import (
+	"github.com/coreyvan/backend-takehome/internal/ingest"
+	"go.uber.org/zap"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// getIf makes a conditional GET with one precondition header.
+func getIf(t *testing.T, url, key, header, value string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("X-API-Key", key)
+	req.Header.Set(header, value)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { res.Body.Close() })
+	return res
+}
+
+// TestConditionalRequests checks responses are validated by the latest
+// ingest run of the data they're built from.
+func TestConditionalRequests(t *testing.T) {
+	db := newTestDB(t)
+	srv, keys, _ := serveTestAPI(t, db)
+
+	locations := get(t, srv.URL+"/v2/locations", keys["admin"], "")
+	etag, modified := locations.Header.Get("ETag"), locations.Header.Get("Last-Modified")
+	if etag == "" || modified == "" {
+		t.Fatalf("locations have no validators: %v", locations.Header)
+	}
+	if cc := locations.Header.Get("Cache-Control"); cc != "private, max-age=3600" {
+		t.Errorf("Cache-Control = %q", cc)
+	}
+
+	for _, tc := range []struct {
+		header, value string
+		want          int
+	}{
+		{"If-None-Match", etag, http.StatusNotModified},
+		{"If-None-Match", `"other", W/` + etag, http.StatusNotModified},
+		{"If-None-Match", "*", http.StatusNotModified},
+		{"If-None-Match", `"other"`, http.StatusOK},
+		{"If-Modified-Since", modified, http.StatusNotModified},
+		{"If-Modified-Since", time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC).Format(http.TimeFormat), http.StatusOK},
+	} {
+		res := getIf(t, srv.URL+"/v2/locations", keys["admin"], tc.header, tc.value)
+		if res.StatusCode != tc.want {
+			t.Errorf("%s: %s = %d, want %d", tc.header, tc.value, res.StatusCode, tc.want)
+		}
+		if res.StatusCode == http.StatusNotModified && res.Header.Get("ETag") != etag {
+			t.Errorf("304 has ETag %q, want %q", res.Header.Get("ETag"), etag)
+		}
+	}
+
+	// Representations differ by version, format and the customers a key
+	// sees, and so do their ETags.
+	for name, res := range map[string]*http.Response{
+		"v1":       get(t, srv.URL+"/v1/locations", keys["admin"], ""),
+		"csv":      get(t, srv.URL+"/v2/locations", keys["admin"], "text/csv"),
+		"customer": get(t, srv.URL+"/v2/locations", keys["telgraph"], ""),
+	} {
+		if res.Header.Get("ETag") == etag {
+			t.Errorf("%s shares the ETag of the v2 JSON", name)
+		}
+	}
+
+	waybills := get(t, srv.URL+"/v2/waybills", keys["admin"], "")
+	if res := getIf(t, srv.URL+"/v2/waybills", keys["admin"], "If-None-Match", waybills.Header.Get("ETag")); res.StatusCode != http.StatusNotModified {
+		t.Errorf("unchanged waybills = %d, want 304", res.StatusCode)
+	}
+
+	// Reloading waybills leaves their events, and so their validators, alone.
+	events := get(t, srv.URL+"/v2/events", keys["admin"], "")
+	if _, err := ingest.NewIngester(db, zap.NewNop()).ProcessWaybills(filepath.Join(testData(t), "waybills.csv")); err != nil {
+		t.Fatal(err)
+	}
+	if res := getIf(t, srv.URL+"/v2/events", keys["admin"], "If-None-Match", events.Header.Get("ETag")); res.StatusCode != http.StatusNotModified {
+		t.Errorf("events after reloading waybills = %d, want 304", res.StatusCode)
+	}
+	waybills = get(t, srv.URL+"/v2/waybills", keys["admin"], "")
+
+	// Reloading locations changes their validators and those of waybills,
+	// which include them.
+	if _, err := ingest.NewIngester(db, zap.NewNop()).ProcessLocations(filepath.Join(testData(t), "locations.csv")); err != nil {
+		t.Fatal(err)
+	}
+	if res := getIf(t, srv.URL+"/v2/locations", keys["admin"], "If-None-Match", etag); res.StatusCode != http.StatusOK || res.Header.Get("ETag") == etag {
+		t.Errorf("reloaded locations = %d with ETag %s, want 200 with a new one", res.StatusCode, res.Header.Get("ETag"))
+	}
+	if res := getIf(t, srv.URL+"/v2/waybills", keys["admin"], "If-None-Match", waybills.Header.Get("ETag")); res.StatusCode != http.StatusOK {
+		t.Errorf("waybills after reloading locations = %d, want 200", res.StatusCode)
+	}
+}
+
+func TestCacheControl(t *testing.T) {
+	srv, keys, _ := newTestAPI(t)
+
+	for _, tc := range []struct {
+		path    string
+		control string
+		// Validators belong to ingested data, not to problems or to data as
+		// of now.
+		etag bool
+	}{
+		{"/v2/waybills/7", "private, no-cache", true},
+		{"/v2/events", "private, no-cache", true},
+		{"/v2/track/978950", "private, no-cache", false},
+		{"/v2/alerts", "no-store", false},
+		{"/v2/waybills/nonexistent", "no-store", false},
+	} {
+		res := get(t, srv.URL+tc.path, keys["admin"], "")
+		if cc := res.Header.Get("Cache-Control"); cc != tc.control {
+			t.Errorf("%s: Cache-Control = %q, want %q", tc.path, cc, tc.control)
+		}
+		if etag := res.Header.Get("ETag"); (etag != "") != tc.etag {
+			t.Errorf("%s: ETag = %q", tc.path, etag)
+		}
+	}
+}
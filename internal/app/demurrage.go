@@ -0,0 +1,503 @@
+package app
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v2"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const day = 24 * time.Hour
+
+// Cents is an amount of money in hundredths, so line items add up to their
+// totals exactly. It's written as a decimal, as in 75.00.
+type Cents int64
+
+func (c Cents) String() string {
+	sign := ""
+	if c < 0 {
+		sign, c = "-", -c
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, c/100, c%100)
+}
+
+func (c Cents) MarshalJSON() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// UnmarshalYAML reads a decimal with at most two places, such as 75 or 75.50.
+func (c *Cents) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	parsed, err := parseCents(s)
+	if err != nil {
+		return err
+	}
+	*c = parsed
+	return nil
+}
+
+func parseCents(s string) (Cents, error) {
+	units, frac, _ := strings.Cut(strings.TrimSpace(s), ".")
+	if len(frac) > 2 {
+		return 0, fmt.Errorf("amount %s has more than two decimal places", s)
+	}
+	n, err := strconv.ParseInt(units+(frac + "00")[:2], 10, 64)
+	if err != nil || strings.HasPrefix(frac, "-") || strings.HasPrefix(frac, "+") {
+		return 0, fmt.Errorf("amount %s isn't a decimal", s)
+	}
+	return Cents(n), nil
+}
+
+// Tariff prices the time a car sits at a customer facility under an average
+// agreement. The first FreeDays of each placement are free; every started day
+// after that is charged RatePerDay. The first DebitDays of those are debits,
+// which credits can offset in the customer's monthly bill, and the rest are
+// charged straight; zero DebitDays makes every chargeable day a debit.
+// Releasing a car within CreditWithin of placement earns a credit.
+type Tariff struct {
+	FreeDays     int           `yaml:"free_days" json:"free_days"`
+	RatePerDay   Cents         `yaml:"rate_per_day" json:"rate_per_day"`
+	DebitDays    int           `yaml:"debit_days" json:"debit_days"`
+	CreditWithin time.Duration `yaml:"credit_within" json:"-"`
+}
+
+// DemurrageConfig holds the default tariff, per-customer overrides and the
+// event codes that start and end a placement.
+type DemurrageConfig struct {
+	PlacementCode string            `yaml:"placement_code"`
+	ReleaseCode   string            `yaml:"release_code"`
+	Default       Tariff            `yaml:"default"`
+	Customers     map[string]Tariff `yaml:"customers"`
+}
+
+var DefaultDemurrageConfig = DemurrageConfig{
+	PlacementCode: "6007",
+	ReleaseCode:   "6003",
+	Default:       Tariff{FreeDays: 2, RatePerDay: 7500, DebitDays: 4, CreditWithin: day},
+}
+
+// LoadDemurrageConfig reads tariffs from a YAML file, falling back to
+// DefaultDemurrageConfig when the file doesn't exist.
+func LoadDemurrageConfig(path string) (DemurrageConfig, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return DefaultDemurrageConfig, nil
+	}
+	if err != nil {
+		return DemurrageConfig{}, fmt.Errorf("reading demurrage tariffs: %w", err)
+	}
+
+	cfg := DemurrageConfig{
+		PlacementCode: DefaultDemurrageConfig.PlacementCode,
+		ReleaseCode:   DefaultDemurrageConfig.ReleaseCode,
+	}
+	if err := yaml.UnmarshalStrict(b, &cfg); err != nil {
+		return DemurrageConfig{}, fmt.Errorf("parsing demurrage tariffs: %w", err)
+	}
+
+	tariffs := map[string]Tariff{"default": cfg.Default}
+	for customer, t := range cfg.Customers {
+		tariffs[customer] = t
+	}
+	for name, t := range tariffs {
+		if t.FreeDays < 0 || t.RatePerDay < 0 || t.DebitDays < 0 || t.CreditWithin < 0 {
+			return DemurrageConfig{}, fmt.Errorf("demurrage tariff %s has negative values", name)
+		}
+	}
+
+	return cfg, nil
+}
+
+// TariffFor returns the customer's tariff, or the default one.
+func (cfg DemurrageConfig) TariffFor(customer string) Tariff {
+	if t, ok := cfg.Customers[customer]; ok {
+		return t
+	}
+	return cfg.Default
+}
+
+// Placement is one stay of a car at a facility, from an actual placement
+// event to the matching release. ReleasedAt is nil while the car is still
+// placed, in which case the charges accrue up to the time assessed. Debits
+// are the chargeable days credits can offset; Charge is before any credits.
+type Placement struct {
+	WaybillID      string     `json:"waybill_id"`
+	EquipmentID    string     `json:"equipment_id"`
+	Customer       string     `json:"customer"`
+	LocationID     string     `json:"location_id"`
+	PlacedAt       time.Time  `json:"placed_at"`
+	ReleasedAt     *time.Time `json:"released_at"`
+	DwellHours     float64    `json:"dwell_hours"`
+	Days           int        `json:"days"`
+	FreeDays       int        `json:"free_days"`
+	ChargeableDays int        `json:"chargeable_days"`
+	Debits         int        `json:"debits"`
+	Credits        int        `json:"credits"`
+	RatePerDay     Cents      `json:"rate_per_day"`
+	Charge         Cents      `json:"charge"`
+}
+
+// WaybillDemurrage is the demurrage owed on a waybill's placements.
+type WaybillDemurrage struct {
+	WaybillID   string      `json:"waybill_id"`
+	AsOf        time.Time   `json:"as_of"`
+	Placements  []Placement `json:"placements"`
+	TotalCharge Cents       `json:"total_charge"`
+}
+
+// debits counts how many of n chargeable days, starting from the from'th
+// chargeable day of a placement, are debits.
+func (t Tariff) debits(from, n int) int {
+	if t.DebitDays == 0 {
+		return n
+	}
+	left := t.DebitDays - from
+	if left < 0 {
+		return 0
+	}
+	if n < left {
+		return n
+	}
+	return left
+}
+
+// FindPlacements pairs each placement event with the next release of the same
+// car at the same location. Events must be in sighting order and may span
+// several cars and waybills, since a car is often released under the waybill
+// that moves it out rather than the one that brought it in.
+func (cfg DemurrageConfig) FindPlacements(events []Event) []Placement {
+	var placements []Placement
+	for k, e := range events {
+		if e.SightingEventCode != cfg.PlacementCode {
+			continue
+		}
+
+		p := Placement{
+			WaybillID:   e.WaybillID,
+			EquipmentID: e.EquipmentID,
+			LocationID:  e.LocationID,
+			PlacedAt:    e.SightingDate,
+		}
+		for _, next := range events[k+1:] {
+			if next.EquipmentID != e.EquipmentID {
+				continue
+			}
+			if next.SightingEventCode == cfg.PlacementCode {
+				break
+			}
+			if next.SightingEventCode == cfg.ReleaseCode && next.LocationID == e.LocationID {
+				released := next.SightingDate
+				p.ReleasedAt = &released
+				break
+			}
+		}
+		placements = append(placements, p)
+	}
+
+	return placements
+}
+
+// Assess prices a placement under the customer's tariff, counting days up to
+// its release or asOf, whichever comes first.
+func (cfg DemurrageConfig) Assess(p Placement, asOf time.Time) Placement {
+	t := cfg.TariffFor(p.Customer)
+
+	end := asOf
+	if p.ReleasedAt != nil && p.ReleasedAt.Before(asOf) {
+		end = *p.ReleasedAt
+	}
+	if end.Before(p.PlacedAt) {
+		end = p.PlacedAt
+	}
+
+	dwell := end.Sub(p.PlacedAt)
+	p.DwellHours = math.Round(dwell.Hours()*100) / 100
+	p.Days = int(math.Ceil(float64(dwell) / float64(day)))
+	p.FreeDays = t.FreeDays
+	p.ChargeableDays = p.Days - t.FreeDays
+	if p.ChargeableDays < 0 {
+		p.ChargeableDays = 0
+	}
+	p.Debits = t.debits(0, p.ChargeableDays)
+	p.Credits = 0
+	if p.ReleasedAt != nil && !p.ReleasedAt.After(asOf) && dwell <= t.CreditWithin {
+		p.Credits = 1
+	}
+	p.RatePerDay = t.RatePerDay
+	p.Charge = Cents(p.ChargeableDays) * t.RatePerDay
+
+	return p
+}
+
+// assessMonth prices only the chargeable days that start within
+// [start, end), so a placement spanning months is billed once across them.
+// Credits count in the month of release.
+func (cfg DemurrageConfig) assessMonth(p Placement, start, end, asOf time.Time) Placement {
+	t := cfg.TariffFor(p.Customer)
+	if end.After(asOf) {
+		end = asOf
+	}
+
+	full := cfg.Assess(p, asOf)
+	p = full
+	p.ChargeableDays, p.Debits = 0, 0
+	for i := t.FreeDays; i < full.Days; i++ {
+		dayStart := full.PlacedAt.Add(time.Duration(i) * day)
+		if !dayStart.Before(start) && dayStart.Before(end) {
+			p.ChargeableDays++
+			p.Debits += t.debits(i-t.FreeDays, 1)
+		}
+	}
+	if full.ReleasedAt == nil || full.ReleasedAt.Before(start) || !full.ReleasedAt.Before(end) {
+		p.Credits = 0
+	}
+	p.Charge = Cents(p.ChargeableDays) * t.RatePerDay
+
+	return p
+}
+
+// CustomerDemurrage is one customer's bill for a month. Credits offset
+// debits, never below zero, and credits left over expire with the month;
+// NetDebits are the debits left. CreditAmount is what the credits took off,
+// so Amount is the placements' charges less CreditAmount.
+type CustomerDemurrage struct {
+	Customer       string      `json:"customer"`
+	Placements     []Placement `json:"placements"`
+	ChargeableDays int         `json:"chargeable_days"`
+	Debits         int         `json:"debits"`
+	Credits        int         `json:"credits"`
+	NetDebits      int         `json:"net_debits"`
+	RatePerDay     Cents       `json:"rate_per_day"`
+	CreditAmount   Cents       `json:"credit_amount"`
+	Amount         Cents       `json:"amount"`
+}
+
+type DemurrageReport struct {
+	Month     string              `json:"month"`
+	AsOf      time.Time           `json:"as_of"`
+	Customers []CustomerDemurrage `json:"customers"`
+}
+
+// MonthlyReport bills each customer for the chargeable days of placements
+// falling in the month starting at start. Placements must already carry
+// their customer.
+func (cfg DemurrageConfig) MonthlyReport(placements []Placement, start, asOf time.Time) DemurrageReport {
+	end := start.AddDate(0, 1, 0)
+	report := DemurrageReport{Month: start.Format("2006-01"), AsOf: asOf, Customers: []CustomerDemurrage{}}
+
+	byCustomer := make(map[string]*CustomerDemurrage)
+	for _, p := range placements {
+		if !p.PlacedAt.Before(end) || p.PlacedAt.After(asOf) {
+			continue
+		}
+		if p.ReleasedAt != nil && p.ReleasedAt.Before(start) {
+			continue
+		}
+
+		billed := cfg.assessMonth(p, start, end, asOf)
+		if billed.ChargeableDays == 0 && billed.Credits == 0 {
+			continue
+		}
+
+		c, ok := byCustomer[p.Customer]
+		if !ok {
+			c = &CustomerDemurrage{Customer: p.Customer, RatePerDay: cfg.TariffFor(p.Customer).RatePerDay}
+			byCustomer[p.Customer] = c
+		}
+		c.Placements = append(c.Placements, billed)
+		c.ChargeableDays += billed.ChargeableDays
+		c.Debits += billed.Debits
+		c.Credits += billed.Credits
+		c.Amount += billed.Charge
+	}
+
+	for _, c := range byCustomer {
+		c.NetDebits = c.Debits - c.Credits
+		if c.NetDebits < 0 {
+			c.NetDebits = 0
+		}
+		c.CreditAmount = Cents(c.Debits-c.NetDebits) * c.RatePerDay
+		c.Amount -= c.CreditAmount
+		report.Customers = append(report.Customers, *c)
+	}
+	sort.Slice(report.Customers, func(i, j int) bool {
+		return report.Customers[i].Customer < report.Customers[j].Customer
+	})
+
+	return report
+}
+
+// WriteCSV writes one billing row per placement and one for any credits taken
+// off, followed by a total row per customer that is the sum of those rows.
+func (r DemurrageReport) WriteCSV(w *csv.Writer) error {
+	rows := [][]string{{"month", "customer", "waybill_id", "equipment_id", "location_id", "placed_at", "released_at", "chargeable_days", "debits", "credits", "rate_per_day", "amount"}}
+	for _, c := range r.Customers {
+		for _, p := range c.Placements {
+			var released string
+			if p.ReleasedAt != nil {
+				released = p.ReleasedAt.Format(time.RFC3339)
+			}
+			rows = append(rows, []string{
+				r.Month, csvText(c.Customer), csvText(p.WaybillID), csvText(p.EquipmentID), csvText(p.LocationID),
+				p.PlacedAt.Format(time.RFC3339), released,
+				strconv.Itoa(p.ChargeableDays), strconv.Itoa(p.Debits), strconv.Itoa(p.Credits),
+				p.RatePerDay.String(), p.Charge.String(),
+			})
+		}
+		if c.CreditAmount > 0 {
+			rows = append(rows, []string{
+				r.Month, csvText(c.Customer), "CREDIT", "", "", "", "",
+				"", "", strconv.Itoa(c.Credits),
+				c.RatePerDay.String(), (-c.CreditAmount).String(),
+			})
+		}
+		rows = append(rows, []string{
+			r.Month, csvText(c.Customer), "TOTAL", "", "", "", "",
+			strconv.Itoa(c.ChargeableDays), strconv.Itoa(c.Debits), strconv.Itoa(c.Credits),
+			c.RatePerDay.String(), c.Amount.String(),
+		})
+	}
+
+	if err := w.WriteAll(rows); err != nil {
+		return fmt.Errorf("writing csv: %w", err)
+	}
+	return nil
+}
+
+func (h *HTTP) WaybillDemurrage() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var errs []FieldError
+		asOf := demurrageAsOf(c, &errs)
+		if len(errs) > 0 {
+			h.invalid(c, errs...)
+			return
+		}
+
+		waybill, ok := h.findWaybill(c)
+		if !ok {
+			return
+		}
+
+		placements, err := h.placements(c.Request.Context(), waybill.EquipmentID)
+		if err != nil {
+			h.internalError(c, fmt.Errorf("finding placements: %w", err))
+			return
+		}
+
+		res := WaybillDemurrage{WaybillID: waybill.ID, AsOf: asOf, Placements: []Placement{}}
+		for _, p := range placements {
+			if p.WaybillID != waybill.ID || p.PlacedAt.After(asOf) || !canSee(c, p.Customer) {
+				continue
+			}
+			p = h.demurrage.Assess(p, asOf)
+			res.Placements = append(res.Placements, p)
+			res.TotalCharge += p.Charge
+		}
+
+		c.JSON(http.StatusOK, res)
+	}
+}
+
+func (h *HTTP) DemurrageReport() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var errs []FieldError
+		start, err := time.Parse("2006-01", c.Query("month"))
+		if err != nil {
+			errs = append(errs, FieldError{Name: "month", In: "query", Reason: "must be YYYY-MM"})
+		}
+		asOf := demurrageAsOf(c, &errs)
+		if len(errs) > 0 {
+			h.invalid(c, errs...)
+			return
+		}
+
+		placements, err := h.placements(c.Request.Context(), "")
+		if err != nil {
+			h.internalError(c, fmt.Errorf("finding placements: %w", err))
+			return
+		}
+
+		customer := c.Query("customer")
+		var filtered []Placement
+		for _, p := range placements {
+			if (customer == "" || p.Customer == customer) && canSee(c, p.Customer) {
+				filtered = append(filtered, p)
+			}
+		}
+		placements = filtered
+
+		report := h.demurrage.MonthlyReport(placements, start, asOf)
+
+		if c.Query("format") == "csv" || negotiate(c.GetHeader("Accept"), jsonMediaType, csvMediaType) == csvMediaType {
+			c.Header("Content-Type", csvMediaType)
+			c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=demurrage-%s.csv", report.Month))
+			w := csv.NewWriter(c.Writer)
+			if err := report.WriteCSV(w); err != nil {
+				h.log.Sugar().Errorf("writing demurrage csv: %v", err)
+			}
+			return
+		}
+
+		c.JSON(http.StatusOK, report)
+	}
+}
+
+// demurrageAsOf reads the as_of query param, defaulting to now.
+func demurrageAsOf(c *gin.Context, errs *[]FieldError) time.Time {
+	if t := queryTime(c, "as_of", errs); !t.IsZero() {
+		return t
+	}
+	return time.Now().UTC()
+}
+
+// placements finds placements of one car, or of every car when equipmentID
+// is empty, and attributes each to the customer whose fleet the car belonged
+// to when it was placed.
+func (h *HTTP) placements(ctx context.Context, equipmentID string) ([]Placement, error) {
+	events, err := h.events.ListEvents(ctx, Scope{}, EventFilter{
+		EquipmentID: equipmentID,
+		Codes:       []string{h.demurrage.PlacementCode, h.demurrage.ReleaseCode},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("finding placement events: %w", err)
+	}
+
+	placements := h.demurrage.FindPlacements(events)
+	if len(placements) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, 0, len(placements))
+	for _, p := range placements {
+		ids = append(ids, p.EquipmentID)
+	}
+
+	equipment, err := h.equipment.ListEquipment(ctx, Scope{}, EquipmentFilter{EquipmentIDs: ids})
+	if err != nil {
+		return nil, fmt.Errorf("finding equipment: %w", err)
+	}
+	records := make(map[string][]Equipment)
+	for _, e := range equipment {
+		records[e.EquipmentID] = append(records[e.EquipmentID], e)
+	}
+
+	for k, p := range placements {
+		if e, ok := EquipmentAt(records[p.EquipmentID], p.PlacedAt, p.PlacedAt); ok {
+			placements[k].Customer = e.Customer
+		}
+	}
+
+	return placements, nil
+}
@@ -0,0 +1,104 @@
+package app_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"github.com/coreyvan/backend-takehome/internal/app"
+	"math"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestLoadDemurrageConfigReadsDecimalRates(t *testing.T) {
+	cfg, err := app.LoadDemurrageConfig(filepath.Join("..", "..", "demurrage.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.TariffFor("TELGRAPH").RatePerDay; got != 10000 {
+		t.Errorf("TELGRAPH rate = %d cents, want 10000", got)
+	}
+}
+
+// TestDemurrageCSVTotalsAddUp checks each customer's total row is the sum of
+// the rows above it, credits included, at a rate that doesn't divide evenly.
+func TestDemurrageCSVTotalsAddUp(t *testing.T) {
+	cfg := app.DemurrageConfig{Default: app.Tariff{RatePerDay: 3333, CreditWithin: 24 * time.Hour}}
+	at := func(day, hour int) *time.Time {
+		d := time.Date(2021, 8, day, hour, 0, 0, 0, time.UTC)
+		return &d
+	}
+	placements := []app.Placement{
+		{WaybillID: "1", Customer: "A", PlacedAt: *at(1, 0), ReleasedAt: at(1, 12)},
+		{WaybillID: "2", Customer: "A", PlacedAt: *at(2, 0), ReleasedAt: at(5, 0)},
+		{WaybillID: "3", Customer: "B", PlacedAt: *at(3, 0), ReleasedAt: at(3, 1)},
+	}
+	report := cfg.MonthlyReport(placements, time.Date(2021, 8, 1, 0, 0, 0, 0, time.UTC), *at(31, 0))
+
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	if err := report.WriteCSV(w); err != nil {
+		t.Fatal(err)
+	}
+	w.Flush()
+	rows, err := csv.NewReader(&b).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sums := make(map[string]int64)
+	totals := make(map[string]int64)
+	for _, row := range rows[1:] {
+		amount, err := strconv.ParseFloat(row[11], 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cents := int64(math.Round(amount * 100))
+		if row[2] == "TOTAL" {
+			totals[row[1]] = cents
+		} else {
+			sums[row[1]] += cents
+		}
+	}
+
+	// A is charged 1 and 3 days, less a credit for the first release.
+	want := map[string]int64{"A": 9999, "B": 0}
+	for customer, total := range want {
+		if totals[customer] != total || sums[customer] != total {
+			t.Errorf("customer %s: total %d cents, rows sum to %d, want %d", customer, totals[customer], sums[customer], total)
+		}
+	}
+}
+
+// TestMonthlyReportNetsDebitsAgainstCredits checks credits only offset the
+// debit days of placements, with the days after those charged straight, and
+// that credits beyond a month's debits are lost.
+func TestMonthlyReportNetsDebitsAgainstCredits(t *testing.T) {
+	cfg := app.DemurrageConfig{Default: app.Tariff{FreeDays: 1, RatePerDay: 1000, DebitDays: 2, CreditWithin: 24 * time.Hour}}
+	at := func(day int) *time.Time {
+		d := time.Date(2021, 8, day, 0, 0, 0, 0, time.UTC)
+		return &d
+	}
+	placements := []app.Placement{
+		// Five chargeable days, two of them debits.
+		{WaybillID: "1", Customer: "A", PlacedAt: *at(1), ReleasedAt: at(7)},
+		// Three early releases earn three credits.
+		{WaybillID: "2", Customer: "A", PlacedAt: *at(10), ReleasedAt: at(11)},
+		{WaybillID: "3", Customer: "A", PlacedAt: *at(12), ReleasedAt: at(13)},
+		{WaybillID: "4", Customer: "A", PlacedAt: *at(14), ReleasedAt: at(15)},
+	}
+	report := cfg.MonthlyReport(placements, *at(1), *at(31))
+	if len(report.Customers) != 1 {
+		t.Fatalf("report = %+v, want one customer", report)
+	}
+
+	a := report.Customers[0]
+	if a.ChargeableDays != 5 || a.Debits != 2 || a.Credits != 3 || a.NetDebits != 0 {
+		t.Errorf("customer A: %d chargeable days, %d debits, %d credits, %d net debits, want 5, 2, 3 and 0", a.ChargeableDays, a.Debits, a.Credits, a.NetDebits)
+	}
+	// The three straight days are charged; the two debits are offset.
+	if a.CreditAmount != 2000 || a.Amount != 3000 {
+		t.Errorf("customer A: credited %s and billed %s, want 20.00 and 30.00", a.CreditAmount, a.Amount)
+	}
+}
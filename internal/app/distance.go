@@ -0,0 +1,110 @@
+package app
+
+import (
+	"math"
+)
+
+const earthRadiusMiles = 3958.8
+
+// Distance summarizes how far a waybill's car moved.
+type Distance struct {
+	WaybillID              string   `json:"waybill_id"`
+	EquipmentID            string   `json:"equipment_id"`
+	OriginDestinationMiles *float64 `json:"origin_destination_miles"`
+	PathMiles              float64  `json:"path_miles"`
+	LoadedMiles            float64  `json:"loaded_miles"`
+	EmptyMiles             float64  `json:"empty_miles"`
+	Sightings              int      `json:"sightings"`
+	UnlocatedSightings     int      `json:"unlocated_sightings"`
+}
+
+// EquipmentDistance totals how far a car moved across its waybills.
+type EquipmentDistance struct {
+	EquipmentID        string     `json:"equipment_id"`
+	PathMiles          float64    `json:"path_miles"`
+	LoadedMiles        float64    `json:"loaded_miles"`
+	EmptyMiles         float64    `json:"empty_miles"`
+	Sightings          int        `json:"sightings"`
+	UnlocatedSightings int        `json:"unlocated_sightings"`
+	Waybills           []Distance `json:"waybills"`
+}
+
+// Haversine returns the great-circle distance in miles between two
+// latitude/longitude pairs given in degrees.
+func Haversine(lat1, lon1, lat2, lon2 float64) float64 {
+	dLat := radians(lat2 - lat1)
+	dLon := radians(lon2 - lon1)
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(radians(lat1))*math.Cos(radians(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * earthRadiusMiles * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// MilesBetween is the great-circle distance between two locations.
+func MilesBetween(from, to Location) float64 {
+	return Haversine(from.Latitude, from.Longitude, to.Latitude, to.Longitude)
+}
+
+// WaybillDistance computes origin-destination and traveled distances for a
+// waybill. Events must be in sighting order; sightings at locations missing
+// from locations are skipped, so the path joins the nearest located sightings
+// on either side. Each leg is attributed to loaded or empty miles based on the
+// status reported at the sighting the car departed from.
+func WaybillDistance(waybill Waybill, events []Event, locations map[string]Location) Distance {
+	d := Distance{
+		WaybillID:   waybill.ID,
+		EquipmentID: waybill.EquipmentID,
+		Sightings:   len(events),
+	}
+
+	origin, okOrigin := locations[waybill.OriginID]
+	destination, okDestination := locations[waybill.DestinationID]
+	if okOrigin && okDestination {
+		miles := MilesBetween(origin, destination)
+		d.OriginDestinationMiles = &miles
+	}
+
+	var prev *Event
+	var prevLocation Location
+	for k := range events {
+		e := &events[k]
+		loc, ok := locations[e.LocationID]
+		if !ok {
+			d.UnlocatedSightings++
+			continue
+		}
+
+		if prev != nil {
+			miles := MilesBetween(prevLocation, loc)
+			d.PathMiles += miles
+			switch prev.LoadEmptyStatus {
+			case "L":
+				d.LoadedMiles += miles
+			case "E":
+				d.EmptyMiles += miles
+			}
+		}
+
+		prev, prevLocation = e, loc
+	}
+
+	return d
+}
+
+// TotalDistance sums the distances of a car's waybills.
+func TotalDistance(equipmentID string, waybills []Distance) EquipmentDistance {
+	d := EquipmentDistance{EquipmentID: equipmentID, Waybills: waybills}
+	for _, w := range waybills {
+		d.PathMiles += w.PathMiles
+		d.LoadedMiles += w.LoadedMiles
+		d.EmptyMiles += w.EmptyMiles
+		d.Sightings += w.Sightings
+		d.UnlocatedSightings += w.UnlocatedSightings
+	}
+	return d
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
@@ -0,0 +1,72 @@
+package app_test
+
+import (
+	"github.com/coreyvan/backend-takehome/internal/app"
+	"math"
+	"testing"
+)
+
+func TestHaversine(t *testing.T) {
+	for _, tc := range []struct {
+		name                   string
+		lat1, lon1, lat2, lon2 float64
+		want                   float64
+	}{
+		{"same point", 41.8781, -87.6298, 41.8781, -87.6298, 0},
+		// New York City Hall to Los Angeles City Hall.
+		{"new york to los angeles", 40.7128, -74.0060, 34.0522, -118.2437, 2445.6},
+		{"equator to pole", 0, 0, 90, 0, math.Pi / 2 * 3958.8},
+		{"antipodes", 0, 0, 0, 180, math.Pi * 3958.8},
+	} {
+		if got := app.Haversine(tc.lat1, tc.lon1, tc.lat2, tc.lon2); math.Abs(got-tc.want) > 0.1 {
+			t.Errorf("%s: got %.2f miles, want %.1f", tc.name, got, tc.want)
+		}
+		if got := app.Haversine(tc.lat2, tc.lon2, tc.lat1, tc.lon1); math.Abs(got-tc.want) > 0.1 {
+			t.Errorf("%s reversed: got %.2f miles, want %.1f", tc.name, got, tc.want)
+		}
+	}
+}
+
+// TestWaybillDistance checks legs count as loaded or empty by the status at
+// the sighting they leave, skipping sightings at unknown locations.
+func TestWaybillDistance(t *testing.T) {
+	locations := map[string]app.Location{
+		"A": {ID: "A", Latitude: 41.8781, Longitude: -87.6298},
+		"B": {ID: "B", Latitude: 38.6270, Longitude: -90.1994},
+		"C": {ID: "C", Latitude: 41.8781, Longitude: -87.6298},
+	}
+	waybill := app.Waybill{ID: "1", EquipmentID: "TILX200001", OriginID: "A", DestinationID: "C"}
+	events := []app.Event{
+		{LocationID: "A", LoadEmptyStatus: "L"},
+		{LocationID: "unknown", LoadEmptyStatus: "E"},
+		{LocationID: "B", LoadEmptyStatus: "E"},
+		{LocationID: "C", LoadEmptyStatus: "E"},
+	}
+
+	d := app.WaybillDistance(waybill, events, locations)
+	leg := app.Haversine(41.8781, -87.6298, 38.6270, -90.1994)
+	if d.OriginDestinationMiles == nil || *d.OriginDestinationMiles != 0 {
+		t.Errorf("origin to destination = %v, want 0", d.OriginDestinationMiles)
+	}
+	if d.LoadedMiles != leg || d.EmptyMiles != leg || d.PathMiles != 2*leg {
+		t.Errorf("loaded %.1f, empty %.1f, path %.1f miles, want %.1f, %.1f and %.1f", d.LoadedMiles, d.EmptyMiles, d.PathMiles, leg, leg, 2*leg)
+	}
+	if d.Sightings != 4 || d.UnlocatedSightings != 1 {
+		t.Errorf("%d sightings, %d unlocated, want 4 and 1", d.Sightings, d.UnlocatedSightings)
+	}
+
+	waybill.DestinationID = "unknown"
+	if d := app.WaybillDistance(waybill, nil, locations); d.OriginDestinationMiles != nil || d.PathMiles != 0 {
+		t.Errorf("without a located destination or sightings: %+v", d)
+	}
+}
+
+func TestTotalDistance(t *testing.T) {
+	total := app.TotalDistance("TILX200001", []app.Distance{
+		{WaybillID: "1", PathMiles: 10, LoadedMiles: 10, Sightings: 2},
+		{WaybillID: "2", PathMiles: 5, LoadedMiles: 1, EmptyMiles: 4, Sightings: 3, UnlocatedSightings: 1},
+	})
+	if total.PathMiles != 15 || total.LoadedMiles != 11 || total.EmptyMiles != 4 || total.Sightings != 5 || total.UnlocatedSightings != 1 || len(total.Waybills) != 2 {
+		t.Errorf("TotalDistance = %+v", total)
+	}
+}
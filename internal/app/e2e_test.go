@@ -0,0 +1,600 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/coreyvan/backend-takehome/internal/app"
+	"github.com/coreyvan/backend-takehome/internal/config"
+	"github.com/coreyvan/backend-takehome/internal/database"
+	"github.com/coreyvan/backend-takehome/internal/ingest"
+	"github.com/coreyvan/backend-takehome/internal/migrate"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/golden")
+
+// evaluatedAt is the time alerts are evaluated at, so they're stable.
+var evaluatedAt = time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+
+// volatile names response fields that change between runs and are replaced
+// before comparing.
+var volatile = map[string]bool{
+	"secret":          true,
+	"created_at":      true,
+	"acknowledged_at": true,
+	"resolved_at":     true,
+}
+
+// streamWindow bounds how long an event stream is read: long enough for the
+// first batch, shorter than the poll interval.
+const streamWindow = 500 * time.Millisecond
+
+// apiCase is one request against the API. route is the pattern it exercises
+// as registered in HTTP.routes(), so coverage of the router can be checked,
+// or empty for paths that match no route.
+type apiCase struct {
+	name   string
+	method string
+	route  string
+	path   string
+	// key is the API key to send: admin (the default), telgraph, other or
+	// none.
+	key    string
+	header map[string]string
+	body   string
+}
+
+var apiCases = []apiCase{
+	{name: "unauthenticated", route: "/waybills", path: "/waybills", key: "none"},
+	{name: "invalid_key", route: "/waybills", path: "/waybills", header: map[string]string{"X-API-Key": "tg_nope"}},
+	{name: "openapi", route: "/openapi.json", path: "/openapi.json", key: "none"},
+	{name: "docs", route: "/docs", path: "/docs", key: "none"},
+	{name: "metrics", route: "/metrics", path: "/metrics", key: "none"},
+	{name: "healthz", route: "/healthz", path: "/healthz", key: "none"},
+	{name: "readyz", route: "/readyz", path: "/readyz", key: "none"},
+	{name: "route_not_found", path: "/nowhere"},
+	{name: "route_not_found_unauthenticated", path: "/nowhere", key: "none"},
+
+	{name: "equipment", route: "/equipment", path: "/equipment"},
+	{name: "equipment_as_of", route: "/equipment", path: "/equipment?as_of=2021-09-10T00:00:00Z"},
+	{name: "equipment_as_of_offset", route: "/equipment", path: "/equipment?as_of=2021-09-10T02:00:00%2B02:00"},
+	{name: "equipment_as_of_invalid", route: "/equipment", path: "/equipment?as_of=yesterday"},
+	{name: "equipment_other_customer", route: "/equipment", path: "/equipment", key: "other"},
+	{name: "equipment_csv_other_customer", route: "/equipment", path: "/equipment", key: "other", header: map[string]string{"Accept": "text/csv"}},
+
+	{name: "events", route: "/events", path: "/events"},
+	{name: "events_after", route: "/events", path: "/events?after=2021-09-01T00:00:00Z"},
+	{name: "events_after_invalid", route: "/events", path: "/events?after=last-week"},
+	{name: "events_other_customer", route: "/events", path: "/events", key: "other"},
+
+	{name: "events_stream_after", route: "/events/stream", path: "/events/stream?after=2021-08-28T00:00:00Z"},
+	{name: "events_stream_last_event_id", route: "/events/stream", path: "/events/stream", header: map[string]string{"Last-Event-ID": "2021-08-30T16:39:25Z/43281"}},
+	{name: "events_stream_idle", route: "/events/stream", path: "/events/stream?after=2021-09-15T00:00:00Z"},
+	{name: "events_stream_invalid_cursor", route: "/events/stream", path: "/events/stream", header: map[string]string{"Last-Event-ID": "nope"}},
+
+	{name: "locations", route: "/locations", path: "/locations"},
+	{name: "locations_accept_geojson", route: "/locations", path: "/locations", header: map[string]string{"Accept": "application/geo+json"}},
+	{name: "locations_geojson", route: "/locations.geojson", path: "/locations.geojson"},
+
+	{name: "waybills", route: "/waybills", path: "/waybills"},
+	{name: "waybills_telgraph", route: "/waybills", path: "/waybills", key: "telgraph"},
+	{name: "waybills_other_customer", route: "/waybills", path: "/waybills", key: "other"},
+	{name: "waybills_by_number", route: "/waybills", path: "/waybills?waybill_number=999333"},
+	{name: "waybills_by_bill_of_lading", route: "/waybills", path: "/waybills?bill_of_lading_number=145718326"},
+	{name: "waybills_by_equipment", route: "/waybills", path: "/waybills?equipment_id=GATX106454"},
+	{name: "waybills_by_number_none", route: "/waybills", path: "/waybills?waybill_number=123"},
+	{name: "waybills_version_2", route: "/waybills", path: "/waybills", header: map[string]string{"Telegraph-Version": "2"}},
+	{name: "waybills_include", route: "/waybills", path: "/waybills?include=equipment,locations,route"},
+	{name: "waybills_include_other_customer", route: "/waybills", path: "/waybills?include=equipment,events", key: "other"},
+	{name: "waybills_by_equipment_csv", route: "/waybills", path: "/waybills?equipment_id=GATX106454", header: map[string]string{"Accept": "text/csv"}},
+	{name: "waybills_by_equipment_ndjson_version_2", route: "/waybills", path: "/waybills?equipment_id=GATX106454", header: map[string]string{"Accept": "application/x-ndjson", "Telegraph-Version": "2"}},
+	{name: "waybills_csv_include", route: "/waybills", path: "/waybills?include=events", header: map[string]string{"Accept": "text/csv"}},
+
+	{name: "waybill_1", route: "/waybills/:id", path: "/waybills/1"},
+	{name: "waybill_7", route: "/waybills/:id", path: "/waybills/7"},
+	{name: "waybill_missing", route: "/waybills/:id", path: "/waybills/999"},
+	{name: "waybill_non_numeric", route: "/waybills/:id", path: "/waybills/abc"},
+	{name: "waybill_1_version_1", route: "/waybills/:id", path: "/waybills/1", header: map[string]string{"Telegraph-Version": "1"}},
+	{name: "waybill_1_version_2", route: "/waybills/:id", path: "/waybills/1", header: map[string]string{"Telegraph-Version": "2"}},
+	{name: "waybill_1_version_invalid", route: "/waybills/:id", path: "/waybills/1", header: map[string]string{"Telegraph-Version": "3"}},
+	{name: "waybill_7_include_all_version_2", route: "/waybills/:id", path: "/waybills/7?include=route,parties", header: map[string]string{"Telegraph-Version": "2"}},
+	{name: "waybill_7_include_all", route: "/waybills/:id", path: "/waybills/7?include=equipment,events,locations,route,parties"},
+	{name: "waybill_1_include_repeated", route: "/waybills/:id", path: "/waybills/1?include=route&include=parties"},
+	{name: "waybill_7_include_invalid", route: "/waybills/:id", path: "/waybills/7?include=equipment,cars"},
+	{name: "waybill_missing_include", route: "/waybills/:id", path: "/waybills/999?include=events"},
+	{name: "waybill_7_other_customer", route: "/waybills/:id", path: "/waybills/7", key: "other"},
+
+	{name: "waybill_1_equipment", route: "/waybills/:id/equipment", path: "/waybills/1/equipment"},
+	{name: "waybill_7_equipment", route: "/waybills/:id/equipment", path: "/waybills/7/equipment"},
+	{name: "waybill_missing_equipment", route: "/waybills/:id/equipment", path: "/waybills/999/equipment"},
+
+	{name: "waybill_7_events", route: "/waybills/:id/events", path: "/waybills/7/events"},
+	{name: "waybill_7_events_after", route: "/waybills/:id/events", path: "/waybills/7/events?after=2021-08-25T00:00:00Z"},
+	{name: "waybill_7_events_after_invalid", route: "/waybills/:id/events", path: "/waybills/7/events?after=soon"},
+	{name: "waybill_missing_events", route: "/waybills/:id/events", path: "/waybills/999/events"},
+	{name: "waybill_7_events_csv", route: "/waybills/:id/events", path: "/waybills/7/events", header: map[string]string{"Accept": "text/csv"}},
+	{name: "waybill_7_events_ndjson_after", route: "/waybills/:id/events", path: "/waybills/7/events?after=2021-08-21T12:00:00Z", header: map[string]string{"Accept": "application/x-ndjson"}},
+
+	{name: "waybill_7_events_stream", route: "/waybills/:id/events/stream", path: "/waybills/7/events/stream?after=2021-08-01T00:00:00Z"},
+	{name: "waybill_missing_events_stream", route: "/waybills/:id/events/stream", path: "/waybills/999/events/stream"},
+
+	{name: "waybill_7_locations", route: "/waybills/:id/locations", path: "/waybills/7/locations"},
+	{name: "waybill_missing_locations", route: "/waybills/:id/locations", path: "/waybills/999/locations"},
+	{name: "waybill_7_locations_csv", route: "/waybills/:id/locations", path: "/waybills/7/locations", header: map[string]string{"Accept": "text/csv"}},
+
+	{name: "waybill_1_route", route: "/waybills/:id/route", path: "/waybills/1/route"},
+	{name: "waybill_7_route", route: "/waybills/:id/route", path: "/waybills/7/route"},
+	{name: "waybill_2_route_splc", route: "/waybills/:id/route", path: "/waybills/2/route"},
+	{name: "waybill_missing_route", route: "/waybills/:id/route", path: "/waybills/999/route"},
+
+	{name: "waybill_1_parties", route: "/waybills/:id/parties", path: "/waybills/1/parties"},
+	{name: "waybill_7_parties", route: "/waybills/:id/parties", path: "/waybills/7/parties"},
+	{name: "waybill_missing_parties", route: "/waybills/:id/parties", path: "/waybills/999/parties"},
+
+	{name: "equipment_distance", route: "/equipment/:id/distance", path: "/equipment/PMRX346210/distance"},
+	{name: "equipment_distance_handed_over", route: "/equipment/:id/distance", path: "/equipment/TILX200001/distance"},
+	{name: "equipment_distance_other_customer", route: "/equipment/:id/distance", path: "/equipment/TILX200001/distance", key: "other"},
+	{name: "equipment_missing_distance", route: "/equipment/:id/distance", path: "/equipment/NOPE000000/distance"},
+
+	{name: "waybill_3_distance", route: "/waybills/:id/distance", path: "/waybills/3/distance"},
+	{name: "waybill_7_distance", route: "/waybills/:id/distance", path: "/waybills/7/distance"},
+	{name: "waybill_missing_distance", route: "/waybills/:id/distance", path: "/waybills/999/distance"},
+
+	{name: "waybill_7_track", route: "/waybills/:id/track", path: "/waybills/7/track"},
+	{name: "waybill_7_track_geojson", route: "/waybills/:id/track.geojson", path: "/waybills/7/track.geojson"},
+	{name: "waybill_missing_track", route: "/waybills/:id/track", path: "/waybills/999/track"},
+
+	{name: "waybill_7_demurrage", route: "/waybills/:id/demurrage", path: "/waybills/7/demurrage?as_of=2021-10-01T00:00:00Z"},
+	{name: "waybill_7_demurrage_as_of_invalid", route: "/waybills/:id/demurrage", path: "/waybills/7/demurrage?as_of=later"},
+	{name: "waybill_missing_demurrage", route: "/waybills/:id/demurrage", path: "/waybills/999/demurrage?as_of=2021-10-01T00:00:00Z"},
+
+	{name: "track_waybill_number", route: "/track/:reference", path: "/track/978950"},
+	{name: "track_reused_waybill_number", route: "/track/:reference", path: "/track/999333"},
+	{name: "track_reused_waybill_number_as_of", route: "/track/:reference", path: "/track/999333?as_of=2021-08-10T00:00:00Z"},
+	{name: "track_reused_waybill_number_too_early", route: "/track/:reference", path: "/track/999333?as_of=2021-08-01T00:00:00Z"},
+	{name: "track_waybill_number_version_2", route: "/track/:reference", path: "/track/978950", header: map[string]string{"Telegraph-Version": "2"}},
+	{name: "track_bill_of_lading", route: "/track/:reference", path: "/track/TA-72844749"},
+	{name: "track_bill_of_lading_same_date", route: "/track/:reference", path: "/track/NS"},
+	{name: "track_equipment", route: "/track/:reference", path: "/track/GATX106454"},
+	{name: "track_missing", route: "/track/:reference", path: "/track/nope"},
+	{name: "track_other_customer", route: "/track/:reference", path: "/track/978950", key: "other"},
+	{name: "track_as_of_invalid", route: "/track/:reference", path: "/track/978950?as_of=tomorrow"},
+	{name: "demurrage_report", route: "/reports/demurrage", path: "/reports/demurrage?month=2021-09&as_of=2021-10-01T00:00:00Z"},
+	{name: "demurrage_report_csv", route: "/reports/demurrage", path: "/reports/demurrage?month=2021-09&as_of=2021-10-01T00:00:00Z&format=csv"},
+	{name: "demurrage_report_customer", route: "/reports/demurrage", path: "/reports/demurrage?month=2021-09&as_of=2021-10-01T00:00:00Z&customer=TELGRAPH"},
+	{name: "demurrage_report_other_customer", route: "/reports/demurrage", path: "/reports/demurrage?month=2021-09&as_of=2021-10-01T00:00:00Z", key: "other"},
+	{name: "demurrage_report_invalid_month", route: "/reports/demurrage", path: "/reports/demurrage?month=September"},
+	{name: "demurrage_report_invalid_month_and_as_of", route: "/reports/demurrage", path: "/reports/demurrage?month=September&as_of=later"},
+
+	{name: "subscription_create", method: http.MethodPost, route: "/subscriptions", path: "/subscriptions", body: `{"target_url": "https://example.com/hooks", "event_codes": ["6016"]}`},
+	{name: "subscription_create_invalid_url", method: http.MethodPost, route: "/subscriptions", path: "/subscriptions", body: `{"target_url": "example.com"}`},
+	{name: "subscription_create_invalid_fields", method: http.MethodPost, route: "/subscriptions", path: "/subscriptions", body: `{"target_url": "ftp://example.com", "event_codes": ["", "6016,6017"]}`},
+	{name: "subscription_create_loopback", method: http.MethodPost, route: "/subscriptions", path: "/subscriptions", body: `{"target_url": "http://localhost:9000/hooks"}`},
+	{name: "subscription_create_metadata", method: http.MethodPost, route: "/subscriptions", path: "/subscriptions", body: `{"target_url": "http://169.254.169.254/latest/meta-data/"}`},
+	{name: "subscription_create_malformed", method: http.MethodPost, route: "/subscriptions", path: "/subscriptions", body: `{"target_url": `},
+	{name: "subscription_create_other_customer", method: http.MethodPost, route: "/subscriptions", path: "/subscriptions", key: "other", body: `{"target_url": "https://example.com/hooks", "customer": "TELGRAPH"}`},
+	{name: "subscriptions", route: "/subscriptions", path: "/subscriptions"},
+	{name: "subscription_1", route: "/subscriptions/:id", path: "/subscriptions/1"},
+	{name: "subscription_non_numeric", route: "/subscriptions/:id", path: "/subscriptions/abc"},
+	{name: "subscription_1_deliveries", route: "/subscriptions/:id/deliveries", path: "/subscriptions/1/deliveries"},
+	{name: "subscription_1_dead_letters", route: "/subscriptions/:id/dead-letters", path: "/subscriptions/1/dead-letters"},
+	{name: "subscription_1_delete", method: http.MethodDelete, route: "/subscriptions/:id", path: "/subscriptions/1"},
+	{name: "subscription_1_deleted", route: "/subscriptions/:id", path: "/subscriptions/1"},
+
+	{name: "alerts", route: "/alerts", path: "/alerts"},
+	{name: "alerts_open_dwell", route: "/alerts", path: "/alerts?status=open&rule=long-dwell"},
+	{name: "alerts_other_customer", route: "/alerts", path: "/alerts", key: "other"},
+	{name: "alerts_invalid_status", route: "/alerts", path: "/alerts?status=closed"},
+	{name: "alert_1", route: "/alerts/:id", path: "/alerts/1"},
+	{name: "alert_missing", route: "/alerts/:id", path: "/alerts/999"},
+	{name: "alert_non_numeric", route: "/alerts/:id", path: "/alerts/abc"},
+	{name: "alert_1_acknowledge", method: http.MethodPost, route: "/alerts/:id/acknowledge", path: "/alerts/1/acknowledge"},
+	{name: "alert_1_acknowledge_again", method: http.MethodPost, route: "/alerts/:id/acknowledge", path: "/alerts/1/acknowledge"},
+	{name: "alert_1_resolve", method: http.MethodPost, route: "/alerts/:id/resolve", path: "/alerts/1/resolve"},
+
+	{name: "graphql_waybill_events_locations", method: http.MethodPost, route: "/graphql", path: "/graphql", body: `{"query": "{ waybill(id: \"7\") { waybill_number routes { scac junction } equipment { customer fleet } origin { city } destination { city } events { id sighting_date location { station state } } } }"}`},
+	{name: "graphql_events_after", method: http.MethodPost, route: "/graphql", path: "/graphql", body: `{"query": "query($after: DateTime) { waybill(id: \"7\") { events(after: $after) { id posting_date } } }", "variables": {"after": "2021-08-20T00:00:00Z"}}`},
+	{name: "graphql_events_after_invalid", method: http.MethodPost, route: "/graphql", path: "/graphql", body: `{"query": "{ events(after: \"last-week\") { id } }"}`},
+	{name: "graphql_equipment_waybills", method: http.MethodPost, route: "/graphql", path: "/graphql", body: `{"query": "{ equipment(as_of: \"2021-09-10T00:00:00Z\") { equipment_id date_removed waybills { id waybill_date events { id } } } }"}`},
+	{name: "graphql_equipment_waybills_other_customer", method: http.MethodPost, route: "/graphql", path: "/graphql", key: "other", body: `{"query": "{ equipment { equipment_id waybills { id } } waybill(id: \"7\") { id } }"}`},
+	{name: "graphql_event_waybill", method: http.MethodPost, route: "/graphql", path: "/graphql", key: "telgraph", body: `{"query": "query Latest { events(after: \"2021-08-01T00:00:00Z\") { id waybill { waybill_number } } }", "operationName": "Latest"}`},
+	{name: "graphql_too_deep", method: http.MethodPost, route: "/graphql", path: "/graphql", body: `{"query": "{ equipment { waybills { events { waybill { events { waybill { events { waybill { id } } } } } } } } }"}`},
+	{name: "graphql_too_complex", method: http.MethodPost, route: "/graphql", path: "/graphql", body: `{"query": "{ equipment { waybills { events { waybill { id } location { id city state station } } } } }"}`},
+	{name: "graphql_unknown_field", method: http.MethodPost, route: "/graphql", path: "/graphql", body: `{"query": "{ waybills { cars } }"}`},
+	{name: "graphql_missing_query", method: http.MethodPost, route: "/graphql", path: "/graphql", body: `{"variables": {}}`},
+	{name: "graphql_malformed", method: http.MethodPost, route: "/graphql", path: "/graphql", body: `{"query": `},
+}
+
+func TestMain(m *testing.M) {
+	// The request log would drown out test failures.
+	gin.DefaultWriter = io.Discard
+	os.Exit(m.Run())
+}
+
+// backends serve the API over each store, which must respond alike. The
+// first, the database, is the reference: -update writes the golden files from
+// it and every route must be covered against it.
+var backends = []struct {
+	name  string
+	serve func(t *testing.T) (*httptest.Server, map[string]string, map[string]bool)
+}{
+	{"gorm", newTestAPI},
+	{"memory", newMemoryTestAPI},
+}
+
+// backendSpecific names cases whose response describes the backend rather
+// than the data, so they're only compared against the reference.
+var backendSpecific = map[string]bool{
+	"openapi": true,
+	"readyz":  true,
+}
+
+// TestAPIGolden runs apiCases in order against the API over the bundled
+// dataset, once per backend, and compares each response with
+// testdata/golden/<name>.json. Run with -update to rewrite them.
+func TestAPIGolden(t *testing.T) {
+	for k, b := range backends {
+		reference := k == 0
+		t.Run(b.name, func(t *testing.T) {
+			srv, keys, routes := b.serve(t)
+
+			covered := make(map[string]bool)
+			for _, tc := range apiCases {
+				method := tc.method
+				if method == "" {
+					method = http.MethodGet
+				}
+
+				if tc.route != "" {
+					route := method + " " + tc.route
+					if !routes[route] {
+						// Only the database serves subscriptions and
+						// alerts.
+						if reference {
+							t.Fatalf("%s: route %s isn't registered", tc.name, route)
+						}
+						continue
+					}
+					covered[route] = true
+				}
+				if !reference && backendSpecific[tc.name] {
+					continue
+				}
+
+				t.Run(tc.name, func(t *testing.T) {
+					got := do(t, srv, keys, method, tc)
+					checkGolden(t, filepath.Join("testdata", "golden", tc.name+".json"), got, reference && *update)
+				})
+			}
+
+			// TestVersionedRoutes replays the cases under each version's prefix.
+			for route := range routes {
+				if !covered[unversioned(route)] {
+					t.Errorf("route %s has no case in apiCases", route)
+				}
+			}
+		})
+	}
+}
+
+// testKeys are the customers each test API key is bound to, by name.
+var testKeys = map[string][]string{
+	"admin":    {app.AllCustomers},
+	"telgraph": {"TELGRAPH"},
+	"other":    {"OTHERCO"},
+}
+
+// newTestAPI ingests testData into a throwaway SQLite database and serves
+// the API over it. It returns the server, the plaintext API keys by name and
+// the registered routes.
+func newTestAPI(t *testing.T) (*httptest.Server, map[string]string, map[string]bool) {
+	t.Helper()
+	return serveTestAPI(t, newTestDB(t))
+}
+
+// newTestDB ingests testData into a throwaway SQLite database and evaluates
+// alerts over it.
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	ctx := context.Background()
+	log := zap.NewNop()
+
+	cfg := config.Default()
+	cfg.DB.DSN = "sqlite://" + filepath.Join(t.TempDir(), "e2e.db")
+	db, err := database.Open(cfg.DB)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := migrate.New(db, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	i := ingest.NewIngester(db, log)
+	data := testData(t)
+	steps := []struct {
+		file    string
+		process func(string) (int, error)
+	}{
+		{"locations.csv", i.ProcessLocations},
+		{"equipment.csv", i.ProcessEquipment},
+		{"waybills.csv", i.ProcessWaybills},
+		{"events.csv", i.ProcessEvents},
+	}
+	for _, s := range steps {
+		if _, err := s.process(filepath.Join(data, s.file)); err != nil {
+			t.Fatalf("ingesting %s: %v", s.file, err)
+		}
+	}
+
+	rules, err := app.LoadAlertConfig(filepath.Join("..", "..", "alerts.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.NewAlertEngine(db, log, rules).Evaluate(ctx, evaluatedAt); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// testData writes data/*.csv, with the rows of testdata/fixtures appended, to
+// a directory for tests to ingest. The fixtures cover what the sample data
+// doesn't, like a car handed from one customer to another.
+func testData(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for _, name := range []string{"locations.csv", "equipment.csv", "waybills.csv", "events.csv"} {
+		b, err := os.ReadFile(filepath.Join("..", "..", "data", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fixture, err := os.ReadFile(filepath.Join("testdata", "fixtures", name)); err == nil {
+			_, rows, _ := strings.Cut(string(fixture), "\n")
+			b = append(append(bytes.TrimRight(b, "\n"), '\n'), rows...)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), b, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// serveTestAPI serves the API over db, reading through the read cache, with an
+// unrestricted key and a key for each of two customers.
+func serveTestAPI(t *testing.T, db *gorm.DB) (*httptest.Server, map[string]string, map[string]bool) {
+	t.Helper()
+
+	keys := make(map[string]string)
+	for name, customers := range testKeys {
+		key, _, err := app.CreateAPIKey(db, name, customers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[name] = key
+	}
+
+	// Tests reload data and expect it read straight away.
+	cached := app.NewCachedStore(app.NewGormStore(db).Stores(), app.NewLRUCache(100, time.Minute))
+	cached.RevisionTTL = 0
+	return serveHTTP(t, app.NewHTTP(zap.NewNop(), config.Default().HTTP, cached.Stores(), db), keys)
+}
+
+// newMemoryTestAPI serves the API over testData loaded into a MemoryStore,
+// with the same keys as serveTestAPI.
+func newMemoryTestAPI(t *testing.T) (*httptest.Server, map[string]string, map[string]bool) {
+	t.Helper()
+
+	store, err := ingest.LoadMemoryStore(testData(t), zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := make(map[string]string)
+	for name, customers := range testKeys {
+		key, _, err := store.CreateAPIKey(name, customers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[name] = key
+	}
+
+	stores := app.NewCachedStore(store.Stores(), app.NewLRUCache(100, time.Minute)).Stores()
+	return serveHTTP(t, app.NewHTTP(zap.NewNop(), config.Default().HTTP, stores, nil), keys)
+}
+
+// serveHTTP serves h for the test and returns the server, the keys and the
+// registered routes.
+func serveHTTP(t *testing.T, h *app.HTTP, keys map[string]string) (*httptest.Server, map[string]string, map[string]bool) {
+	t.Helper()
+
+	routes := make(map[string]bool)
+	for _, r := range h.Routes() {
+		routes[r.Method+" "+r.Path] = true
+	}
+
+	srv := httptest.NewServer(h.Handler())
+	t.Cleanup(srv.Close)
+
+	return srv, keys, routes
+}
+
+// response is what's compared against a golden file. JSON bodies are kept as
+// JSON so the files diff readably; anything else is kept as text.
+type response struct {
+	Status      int         `json:"status"`
+	ContentType string      `json:"content_type,omitempty"`
+	Body        interface{} `json:"body"`
+
+	header http.Header
+}
+
+func do(t *testing.T, srv *httptest.Server, keys map[string]string, method string, tc apiCase) response {
+	t.Helper()
+
+	// Streams never end on their own, so they're read for a fixed window.
+	ctx, cancel := context.WithTimeout(context.Background(), streamWindow)
+	defer cancel()
+	if !strings.Contains(tc.route, "/stream") {
+		ctx = context.Background()
+	}
+
+	var body io.Reader
+	if tc.body != "" {
+		body = strings.NewReader(tc.body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, srv.URL+tc.path, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tc.body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	// A fixed request ID keeps it stable in problem responses.
+	req.Header.Set(app.RequestIDHeader, tc.name)
+
+	switch tc.key {
+	case "none":
+	case "":
+		req.Header.Set("X-API-Key", keys["admin"])
+	default:
+		req.Header.Set("X-API-Key", keys[tc.key])
+	}
+	for k, v := range tc.header {
+		req.Header.Set(k, v)
+	}
+
+	res, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	b, err := io.ReadAll(res.Body)
+	if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal(err)
+	}
+
+	if id := res.Header.Get(app.RequestIDHeader); id != tc.name {
+		t.Errorf("%s header = %q, want %q", app.RequestIDHeader, id, tc.name)
+	}
+
+	got := response{Status: res.StatusCode, ContentType: res.Header.Get("Content-Type"), header: res.Header}
+
+	var v interface{}
+	if strings.Contains(got.ContentType, "json") && json.Unmarshal(b, &v) == nil {
+		got.Body = scrub(v)
+	} else {
+		got.Body = string(b)
+	}
+	return got
+}
+
+// get requests url with an API key and, unless it's empty, an Accept header.
+func get(t *testing.T, url, key, accept string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("X-API-Key", key)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { res.Body.Close() })
+	return res
+}
+
+// scrub replaces volatile fields so responses compare across runs.
+func scrub(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, field := range v {
+			// Objects are left alone: the OpenAPI document has schemas
+			// named like the volatile fields.
+			if _, object := field.(map[string]interface{}); volatile[k] && field != nil && !object {
+				v[k] = "<volatile>"
+				continue
+			}
+			v[k] = scrub(field)
+		}
+	case []interface{}:
+		for k := range v {
+			v[k] = scrub(v[k])
+		}
+	}
+	return v
+}
+
+func checkGolden(t *testing.T, path string, got response, update bool) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(got); err != nil {
+		t.Fatal(err)
+	}
+	b := buf.Bytes()
+
+	if update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, b, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading golden file (run with -update to create it): %v", err)
+	}
+	if !bytes.Equal(b, want) {
+		t.Errorf("response differs from %s (run with -update to accept):\n%s", path, diff(string(want), string(b)))
+	}
+}
+
+// diff shows the first differing line of two golden files with some context.
+func diff(want, got string) string {
+	w, g := strings.Split(want, "\n"), strings.Split(got, "\n")
+	for k := 0; k < len(w) || k < len(g); k++ {
+		var wl, gl string
+		if k < len(w) {
+			wl = w[k]
+		}
+		if k < len(g) {
+			gl = g[k]
+		}
+		if wl != gl {
+			return fmt.Sprintf("line %d:\n-%s\n+%s", k+1, wl, gl)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,242 @@
+package app
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Media types list endpoints export rows in, besides JSON.
+const (
+	jsonMediaType   = "application/json"
+	csvMediaType    = "text/csv"
+	ndjsonMediaType = "application/x-ndjson"
+)
+
+// csvTimeFormat is how exported CSV files write times. It's the format of the
+// fixtures, which ingestion parses along with any fractional seconds.
+const csvTimeFormat = "2006-01-02 15:04:05.999999999"
+
+// exportMediaType returns the export format the Accept header prefers, or
+// an empty string for JSON.
+func exportMediaType(c *gin.Context) string {
+	switch media := negotiate(c.GetHeader("Accept"), jsonMediaType, csvMediaType, ndjsonMediaType); media {
+	case csvMediaType, ndjsonMediaType:
+		return media
+	}
+	return ""
+}
+
+// negotiate returns the offer the Accept header prefers. Each offer takes the
+// q-value of the most specific media range matching it, and ties go to the
+// earlier offer. Without an Accept header that's the first offer; it's empty
+// when every offer has q=0.
+func negotiate(accept string, offers ...string) string {
+	if strings.TrimSpace(accept) == "" {
+		return offers[0]
+	}
+
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		q, specificity := 0.0, -1
+		for _, r := range strings.Split(accept, ",") {
+			params := strings.Split(r, ";")
+			media := strings.ToLower(strings.TrimSpace(params[0]))
+			s := mediaRangeSpecificity(media, offer)
+			if s <= specificity {
+				continue
+			}
+			rangeQ, ok := qValue(params[1:])
+			if !ok {
+				continue
+			}
+			q, specificity = rangeQ, s
+		}
+		if q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+// mediaRangeSpecificity ranks how closely a media range such as text/* matches
+// a media type: 2 for the type itself, 1 for its type's wildcard, 0 for */*
+// and -1 when it doesn't match.
+func mediaRangeSpecificity(media, offer string) int {
+	switch {
+	case media == offer:
+		return 2
+	case strings.HasSuffix(media, "/*") && strings.HasPrefix(offer, strings.TrimSuffix(media, "*")):
+		if media == "*/*" {
+			return 0
+		}
+		return 1
+	}
+	return -1
+}
+
+// qValue reads the q parameter of a media range, 1 by default.
+func qValue(params []string) (float64, bool) {
+	for _, p := range params {
+		name, value, _ := strings.Cut(strings.TrimSpace(p), "=")
+		if !strings.EqualFold(strings.TrimSpace(name), "q") {
+			continue
+		}
+		q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || q < 0 || q > 1 {
+			return 0, false
+		}
+		return q, true
+	}
+	return 1, true
+}
+
+// exporter writes the rows of a list endpoint one at a time, as CSV with the
+// columns ingestion reads or as newline-delimited JSON. Nothing is written
+// until the first row or close, so errors before then can still be reported
+// as problems.
+type exporter struct {
+	c       *gin.Context
+	media   string
+	name    string
+	row     reflect.Type
+	started bool
+	csv     *csv.Writer
+	json    *json.Encoder
+}
+
+// newExporter exports rows of the same type as row, named name in the
+// download's filename.
+func newExporter(c *gin.Context, media, name string, row interface{}) *exporter {
+	return &exporter{c: c, media: media, name: name, row: reflect.TypeOf(row)}
+}
+
+func (e *exporter) start() error {
+	if e.started {
+		return nil
+	}
+	e.started = true
+
+	extension := "csv"
+	if e.media == ndjsonMediaType {
+		extension = "ndjson"
+	}
+	e.c.Header("Content-Type", e.media)
+	e.c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s.%s", e.name, extension))
+	e.c.Status(http.StatusOK)
+
+	if e.media == ndjsonMediaType {
+		e.json = json.NewEncoder(e.c.Writer)
+		return nil
+	}
+	e.csv = csv.NewWriter(e.c.Writer)
+	return e.csv.Write(csvHeader(e.row))
+}
+
+// write exports a row. CSV rows must be of the exporter's row type; NDJSON
+// rows can be any representation of it.
+func (e *exporter) write(row interface{}) error {
+	if err := e.start(); err != nil {
+		return err
+	}
+	if e.json != nil {
+		return e.json.Encode(row)
+	}
+	return e.csv.Write(csvRecord(reflect.ValueOf(row)))
+}
+
+// close finishes the export, writing the CSV header when there were no rows.
+func (e *exporter) close() error {
+	if err := e.start(); err != nil {
+		return err
+	}
+	if e.csv != nil {
+		e.csv.Flush()
+		return e.csv.Error()
+	}
+	return nil
+}
+
+// export streams the rows each writes in the format the request asked for.
+// A failure before any row was sent is a problem; after that the response is
+// cut short and the error only logged.
+func (h *HTTP) export(c *gin.Context, media, name string, row interface{}, each func(write func(interface{}) error) error) {
+	e := newExporter(c, media, name, row)
+	err := each(e.write)
+	if err == nil {
+		err = e.close()
+	}
+	if err == nil {
+		return
+	}
+	if !e.started {
+		h.internalError(c, fmt.Errorf("exporting %s: %w", name, err))
+		return
+	}
+	h.log.Sugar().Errorw(fmt.Sprintf("exporting %s: %v", name, err), "request_id", c.GetString(requestIDKey), "path", c.Request.URL.Path)
+}
+
+// exportSlice exports rows already read, a slice of the row type.
+func (h *HTTP) exportSlice(c *gin.Context, media, name string, rows interface{}) {
+	v := reflect.ValueOf(rows)
+	h.export(c, media, name, reflect.Zero(v.Type().Elem()).Interface(), func(write func(interface{}) error) error {
+		for k := 0; k < v.Len(); k++ {
+			if err := write(v.Index(k).Interface()); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// csvHeader lists the csv tags of a model's fields.
+func csvHeader(t reflect.Type) []string {
+	var header []string
+	for k := 0; k < t.NumField(); k++ {
+		if name := t.Field(k).Tag.Get("csv"); name != "" && name != "-" {
+			header = append(header, name)
+		}
+	}
+	return header
+}
+
+// csvRecord formats a model's csv-tagged fields as ingestion parses them.
+// Zero times are left empty, and text is escaped by csvText.
+func csvRecord(v reflect.Value) []string {
+	var record []string
+	for k := 0; k < v.NumField(); k++ {
+		if name := v.Type().Field(k).Tag.Get("csv"); name == "" || name == "-" {
+			continue
+		}
+		switch field := v.Field(k).Interface().(type) {
+		case time.Time:
+			if field.IsZero() {
+				record = append(record, "")
+			} else {
+				record = append(record, field.UTC().Format(csvTimeFormat))
+			}
+		case float64:
+			record = append(record, strconv.FormatFloat(field, 'f', -1, 64))
+		case string:
+			record = append(record, csvText(field))
+		default:
+			record = append(record, fmt.Sprint(field))
+		}
+	}
+	return record
+}
+
+// csvText quotes text a spreadsheet would run as a formula with a leading
+// apostrophe, which spreadsheets hide.
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
@@ -0,0 +1,153 @@
+package app_test
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"github.com/coreyvan/backend-takehome/internal/app"
+	"github.com/coreyvan/backend-takehome/internal/ingest"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// TestExportRoundTrips checks CSV exports of the ingested tables read back
+// into the rows they were ingested from.
+func TestExportRoundTrips(t *testing.T) {
+	srv, keys, _ := newTestAPI(t)
+	log := zap.NewNop()
+	data := testData(t)
+
+	read := map[string]func(string) (interface{}, error){
+		"equipment": func(f string) (interface{}, error) { return ingest.ReadEquipment(f, log) },
+		"events":    func(f string) (interface{}, error) { return ingest.ReadEvents(f, log) },
+		"locations": func(f string) (interface{}, error) { return ingest.ReadLocations(f) },
+		"waybills":  func(f string) (interface{}, error) { return ingest.ReadWaybills(f, log) },
+	}
+	for name, read := range read {
+		t.Run(name, func(t *testing.T) {
+			exported := filepath.Join(t.TempDir(), name+".csv")
+			res := get(t, srv.URL+"/v2/"+name, keys["admin"], "text/csv")
+			if res.StatusCode != http.StatusOK || res.Header.Get("Content-Type") != "text/csv" {
+				t.Fatalf("export = %d %s", res.StatusCode, res.Header.Get("Content-Type"))
+			}
+			f, err := os.Create(exported)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := io.Copy(f, res.Body); err != nil {
+				t.Fatal(err)
+			}
+			f.Close()
+
+			got, err := read(exported)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := read(filepath.Join(data, name+".csv"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			g, w := sortedJSON(t, got), sortedJSON(t, want)
+			if len(g) != len(w) {
+				t.Fatalf("exported %d rows, ingested %d", len(g), len(w))
+			}
+			for k := range g {
+				if g[k] != w[k] {
+					t.Fatalf("exported row differs:\n got %s\nwant %s", g[k], w[k])
+				}
+			}
+		})
+	}
+}
+
+// TestExportNDJSON checks NDJSON exports have a line per item of the JSON
+// list.
+func TestExportNDJSON(t *testing.T) {
+	srv, keys, _ := newTestAPI(t)
+
+	for _, path := range []string{"/v2/events", "/v2/waybills", "/v1/waybills"} {
+		var want []interface{}
+		if err := json.NewDecoder(get(t, srv.URL+path, keys["telgraph"], "").Body).Decode(&want); err != nil {
+			t.Fatal(err)
+		}
+
+		var got []interface{}
+		lines := bufio.NewScanner(get(t, srv.URL+path, keys["telgraph"], "application/x-ndjson").Body)
+		lines.Buffer(nil, 1<<20)
+		for lines.Scan() {
+			var v interface{}
+			if err := json.Unmarshal(lines.Bytes(), &v); err != nil {
+				t.Fatalf("%s: %v", path, err)
+			}
+			got = append(got, v)
+		}
+		if len(got) == 0 || !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: NDJSON export differs from the JSON list", path)
+		}
+	}
+}
+
+// sortedJSON marshals each row of a slice, sorted so order doesn't matter.
+func sortedJSON(t *testing.T, rows interface{}) []string {
+	v := reflect.ValueOf(rows)
+	out := make([]string, v.Len())
+	for k := range out {
+		b, err := json.Marshal(v.Index(k).Interface())
+		if err != nil {
+			t.Fatal(err)
+		}
+		out[k] = string(b)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func TestExportNegotiatesAccept(t *testing.T) {
+	srv, keys, _ := newTestAPI(t)
+
+	for accept, want := range map[string]string{
+		"":                                     "application/json; charset=utf-8",
+		"*/*":                                  "application/json; charset=utf-8",
+		"application/json, text/csv;q=0":       "application/json; charset=utf-8",
+		"text/csv;q=0.5, application/x-ndjson": "application/x-ndjson",
+		"text/*":                               "text/csv",
+		"text/csv, application/geo+json;q=0.5": "text/csv",
+		"application/geo+json, text/csv;q=0.9": "application/geo+json",
+		"text/csv;q=0, */*;q=0.1":              "application/json; charset=utf-8",
+		"application/x-ndjson;q=0.2, text/csv;q=0.3": "text/csv",
+	} {
+		if got := get(t, srv.URL+"/v2/locations", keys["admin"], accept).Header.Get("Content-Type"); got != want {
+			t.Errorf("Accept %q: Content-Type = %q, want %q", accept, got, want)
+		}
+	}
+}
+
+// TestExportEscapesFormulas checks text a spreadsheet would run as a formula
+// is exported inert.
+func TestExportEscapesFormulas(t *testing.T) {
+	db := newTestDB(t)
+	if err := db.Model(&app.Location{}).Where("id = ?", "2284").Update("city", `=HYPERLINK("http://example.com")`).Error; err != nil {
+		t.Fatal(err)
+	}
+	srv, keys, _ := serveTestAPI(t, db)
+
+	rows, err := csv.NewReader(get(t, srv.URL+"/v2/locations", keys["admin"], "text/csv").Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range rows {
+		if row[0] == "2284" {
+			if row[1] != `'=HYPERLINK("http://example.com")` {
+				t.Errorf("city exported as %q", row[1])
+			}
+			return
+		}
+	}
+	t.Error("location 2284 wasn't exported")
+}
@@ -0,0 +1,144 @@
+package app
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+const geoJSONMediaType = "application/geo+json"
+
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+type Feature struct {
+	Type       string                 `json:"type"`
+	ID         string                 `json:"id,omitempty"`
+	Geometry   Geometry               `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// Geometry holds a GeoJSON Point ([lon, lat]) or LineString ([][lon, lat]).
+type Geometry struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
+
+func NewFeatureCollection() FeatureCollection {
+	return FeatureCollection{Type: "FeatureCollection", Features: []Feature{}}
+}
+
+func PointFeature(id string, lon, lat float64, properties map[string]interface{}) Feature {
+	return Feature{
+		Type:       "Feature",
+		ID:         id,
+		Geometry:   Geometry{Type: "Point", Coordinates: []float64{lon, lat}},
+		Properties: properties,
+	}
+}
+
+func LineStringFeature(id string, coordinates [][]float64, properties map[string]interface{}) Feature {
+	return Feature{
+		Type:       "Feature",
+		ID:         id,
+		Geometry:   Geometry{Type: "LineString", Coordinates: coordinates},
+		Properties: properties,
+	}
+}
+
+// LocationFeature renders a location as a point with its attributes as properties.
+func LocationFeature(l Location, properties map[string]interface{}) Feature {
+	if properties == nil {
+		properties = map[string]interface{}{}
+	}
+	properties["location_id"] = l.ID
+	properties["city"] = l.City
+	properties["city_long"] = l.CityLong
+	properties["station"] = l.Station
+	properties["fsac"] = l.FSAC
+	properties["scac"] = l.SCAC
+	properties["splc"] = l.SPLC
+	properties["state"] = l.State
+	properties["time_zone"] = l.Timezone
+	properties["country"] = l.Country
+
+	return PointFeature(l.ID, l.Longitude, l.Latitude, properties)
+}
+
+// LocationsGeoJSON renders locations as a point FeatureCollection.
+func LocationsGeoJSON(locations []Location) FeatureCollection {
+	fc := NewFeatureCollection()
+	for _, l := range locations {
+		fc.Features = append(fc.Features, LocationFeature(l, nil))
+	}
+	return fc
+}
+
+// TrackGeoJSON renders a waybill's movement as a FeatureCollection holding a
+// LineString through its located sightings in time order, a point per
+// sighting carrying the event's properties, and origin/destination markers.
+// The LineString is left out with fewer than two located sightings, since
+// GeoJSON requires two positions. Events must already be in sighting order.
+func TrackGeoJSON(waybill Waybill, events []Event, locations map[string]Location) FeatureCollection {
+	fc := NewFeatureCollection()
+
+	coordinates := [][]float64{}
+	var sightings []Feature
+	for _, e := range events {
+		l, ok := locations[e.LocationID]
+		if !ok {
+			continue
+		}
+		coordinates = append(coordinates, []float64{l.Longitude, l.Latitude})
+		sightings = append(sightings, LocationFeature(l, map[string]interface{}{
+			"kind":                     "sighting",
+			"event_id":                 e.ID,
+			"sighting_date":            e.SightingDate,
+			"posting_date":             e.PostingDate,
+			"sighting_event_code":      e.SightingEventCode,
+			"sighting_event_code_text": e.SightingEventCodeText,
+			"reporting_railroad_scac":  e.ReportingRailroadSCAC,
+			"load_empty_status":        e.LoadEmptyStatus,
+			"train_id":                 e.TrainID,
+			"train_alpha_code":         e.TrainAlphaCode,
+		}))
+	}
+
+	if len(coordinates) >= 2 {
+		fc.Features = append(fc.Features, LineStringFeature("track-"+waybill.ID, coordinates, map[string]interface{}{
+			"kind":           "track",
+			"waybill_id":     waybill.ID,
+			"waybill_number": waybill.WaybillNumber,
+			"equipment_id":   waybill.EquipmentID,
+		}))
+	}
+
+	if l, ok := locations[waybill.OriginID]; ok {
+		f := LocationFeature(l, map[string]interface{}{"kind": "origin"})
+		f.ID = "origin-" + l.ID
+		fc.Features = append(fc.Features, f)
+	}
+	if l, ok := locations[waybill.DestinationID]; ok {
+		f := LocationFeature(l, map[string]interface{}{"kind": "destination"})
+		f.ID = "destination-" + l.ID
+		fc.Features = append(fc.Features, f)
+	}
+
+	for _, f := range sightings {
+		f.ID = "sighting-" + f.Properties["event_id"].(string)
+		fc.Features = append(fc.Features, f)
+	}
+
+	return fc
+}
+
+// asGeoJSON lets .geojson routes share handlers with their JSON counterparts.
+func asGeoJSON(c *gin.Context) {
+	c.Request.Header.Set("Accept", geoJSONMediaType)
+	c.Next()
+}
+
+func renderGeoJSON(c *gin.Context, code int, fc FeatureCollection) {
+	c.Header("Content-Type", geoJSONMediaType)
+	c.JSON(code, fc)
+}
@@ -0,0 +1,41 @@
+package app_test
+
+import (
+	"github.com/coreyvan/backend-takehome/internal/app"
+	"reflect"
+	"testing"
+)
+
+// TestTrackGeoJSONNeedsTwoPositions checks a waybill with fewer than two
+// located sightings gets its markers but no LineString, which GeoJSON
+// requires two positions for.
+func TestTrackGeoJSONNeedsTwoPositions(t *testing.T) {
+	locations := map[string]app.Location{
+		"A": {ID: "A", Latitude: 41.8781, Longitude: -87.6298},
+		"B": {ID: "B", Latitude: 38.6270, Longitude: -90.1994},
+	}
+	waybill := app.Waybill{ID: "1", OriginID: "A", DestinationID: "B"}
+
+	for _, tc := range []struct {
+		name   string
+		events []app.Event
+		kinds  []string
+	}{
+		{"no sightings", nil, []string{"origin", "destination"}},
+		{"one sighting", []app.Event{{ID: "1", LocationID: "A"}}, []string{"origin", "destination", "sighting"}},
+		{"one located sighting", []app.Event{{ID: "1", LocationID: "A"}, {ID: "2", LocationID: "unknown"}}, []string{"origin", "destination", "sighting"}},
+		{"two sightings", []app.Event{{ID: "1", LocationID: "A"}, {ID: "2", LocationID: "B"}}, []string{"track", "origin", "destination", "sighting", "sighting"}},
+	} {
+		fc := app.TrackGeoJSON(waybill, tc.events, locations)
+		var kinds []string
+		for _, f := range fc.Features {
+			kinds = append(kinds, f.Properties["kind"].(string))
+			if f.Geometry.Type == "LineString" && len(f.Geometry.Coordinates.([][]float64)) < 2 {
+				t.Errorf("%s: LineString %s has fewer than two positions", tc.name, f.ID)
+			}
+		}
+		if !reflect.DeepEqual(kinds, tc.kinds) {
+			t.Errorf("%s: features %v, want %v", tc.name, kinds, tc.kinds)
+		}
+	}
+}
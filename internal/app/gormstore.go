@@ -0,0 +1,288 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"time"
+)
+
+// GormStore implements the stores on top of the Postgres or SQLite database.
+// Times are passed in UTC since SQLite compares them as text.
+type GormStore struct {
+	db *gorm.DB
+}
+
+func NewGormStore(db *gorm.DB) *GormStore {
+	return &GormStore{db: db}
+}
+
+// Stores returns a Stores with every store backed by s.
+func (s *GormStore) Stores() Stores {
+	return Stores{Waybills: s, Events: s, Equipment: s, Locations: s, Keys: s, Revisions: s}
+}
+
+func (s *GormStore) ListEquipment(ctx context.Context, scope Scope, filter EquipmentFilter) ([]Equipment, error) {
+	equipment := []Equipment{}
+	if err := s.equipment(ctx, scope, filter).Find(&equipment).Error; err != nil {
+		return nil, fmt.Errorf("finding equipment: %w", err)
+	}
+	return equipment, nil
+}
+
+func (s *GormStore) EachEquipment(ctx context.Context, scope Scope, filter EquipmentFilter, fn func(Equipment) error) error {
+	var e Equipment
+	err := each(s.equipment(ctx, scope, filter), &e, func() error {
+		defer func() { e = Equipment{} }()
+		return fn(e)
+	})
+	if err != nil {
+		return fmt.Errorf("reading equipment: %w", err)
+	}
+	return nil
+}
+
+func (s *GormStore) equipment(ctx context.Context, scope Scope, filter EquipmentFilter) *gorm.DB {
+	where := s.db.WithContext(ctx).Model(&Equipment{})
+	if scope.Restricted {
+		where = where.Where("equipment.customer IN ?", scope.Customers)
+	}
+	if len(filter.EquipmentIDs) > 0 {
+		where = where.Where("equipment.equipment_id IN ?", filter.EquipmentIDs)
+	}
+	if !filter.AsOf.IsZero() {
+		asOf := filter.AsOf.UTC()
+		where = where.Where("equipment.date_added <= ? AND (equipment.date_removed > ? OR equipment.date_removed = ?)", asOf, asOf, time.Time{})
+	}
+	return where.Order("date_added, id")
+}
+
+func (s *GormStore) ListEvents(ctx context.Context, scope Scope, filter EventFilter) ([]Event, error) {
+	events := []Event{}
+	if err := s.events(ctx, scope, filter).Order("sighting_date, id").Find(&events).Error; err != nil {
+		return nil, fmt.Errorf("finding events: %w", err)
+	}
+	return events, nil
+}
+
+func (s *GormStore) EachEvent(ctx context.Context, scope Scope, filter EventFilter, fn func(Event) error) error {
+	var e Event
+	err := each(s.events(ctx, scope, filter).Order("sighting_date, id"), &e, func() error {
+		defer func() { e = Event{} }()
+		return fn(e)
+	})
+	if err != nil {
+		return fmt.Errorf("reading events: %w", err)
+	}
+	return nil
+}
+
+func (s *GormStore) EventsSince(ctx context.Context, scope Scope, filter EventFilter, cursor EventCursor, limit int) ([]Event, error) {
+	events := []Event{}
+	posted := cursor.PostingDate.UTC()
+	where := s.events(ctx, scope, filter)
+	if cursor.ID == "" {
+		where = where.Where("posting_date > ?", posted)
+	} else {
+		where = where.Where("posting_date > ? OR (posting_date = ? AND id > ?)", posted, posted, cursor.ID)
+	}
+	result := where.
+		Order("posting_date, id").
+		Limit(limit).
+		Find(&events)
+	if result.Error != nil {
+		return nil, fmt.Errorf("finding events since %s: %w", cursor, result.Error)
+	}
+	return events, nil
+}
+
+func (s *GormStore) LatestEventCursor(ctx context.Context) (EventCursor, error) {
+	var e Event
+	result := s.db.WithContext(ctx).Order("posting_date DESC, id DESC").Limit(1).Find(&e)
+	if result.Error != nil {
+		return EventCursor{}, fmt.Errorf("finding latest event: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return EventCursor{}, nil
+	}
+	return EventCursor{PostingDate: e.PostingDate, ID: e.ID}, nil
+}
+
+func (s *GormStore) events(ctx context.Context, scope Scope, filter EventFilter) *gorm.DB {
+	where := s.db.WithContext(ctx).Model(&Event{})
+	if scope.Restricted {
+		// A sighting belongs to the customer whose record covered the car
+		// when it was sighted.
+		where = where.Where("EXISTS (SELECT 1 FROM equipment e WHERE e.equipment_id = events.equipment_id AND e.customer IN ? AND e.date_added <= events.sighting_date AND (e.date_removed > events.sighting_date OR e.date_removed = ?))", scope.Customers, time.Time{})
+	}
+	if filter.WaybillID != "" {
+		where = where.Where("events.waybill_id = ?", filter.WaybillID)
+	}
+	if len(filter.WaybillIDs) > 0 {
+		where = where.Where("events.waybill_id IN ?", filter.WaybillIDs)
+	}
+	if filter.EquipmentID != "" {
+		where = where.Where("events.equipment_id = ?", filter.EquipmentID)
+	}
+	if len(filter.Codes) > 0 {
+		where = where.Where("events.sighting_event_code IN ?", filter.Codes)
+	}
+	if !filter.PostedAfter.IsZero() {
+		where = where.Where("events.posting_date > ?", filter.PostedAfter.UTC())
+	}
+	return where
+}
+
+func (s *GormStore) ListLocations(ctx context.Context) ([]Location, error) {
+	locations := []Location{}
+	if err := s.db.WithContext(ctx).Order("id").Find(&locations).Error; err != nil {
+		return nil, fmt.Errorf("finding locations: %w", err)
+	}
+	return locations, nil
+}
+
+func (s *GormStore) EachLocation(ctx context.Context, fn func(Location) error) error {
+	var l Location
+	err := each(s.db.WithContext(ctx).Model(&Location{}).Order("id"), &l, func() error {
+		defer func() { l = Location{} }()
+		return fn(l)
+	})
+	if err != nil {
+		return fmt.Errorf("reading locations: %w", err)
+	}
+	return nil
+}
+
+func (s *GormStore) LocationsByID(ctx context.Context, ids []string) ([]Location, error) {
+	locations := []Location{}
+	if err := s.db.WithContext(ctx).Where("id IN ?", ids).Order("id").Find(&locations).Error; err != nil {
+		return nil, fmt.Errorf("finding locations by id: %w", err)
+	}
+	return locations, nil
+}
+
+func (s *GormStore) ListWaybills(ctx context.Context, scope Scope, filter WaybillFilter) ([]Waybill, error) {
+	waybills := []Waybill{}
+	if err := s.filteredWaybills(ctx, scope, filter).Find(&waybills).Error; err != nil {
+		return nil, fmt.Errorf("finding waybills: %w", err)
+	}
+	return waybills, nil
+}
+
+func (s *GormStore) EachWaybill(ctx context.Context, scope Scope, filter WaybillFilter, fn func(Waybill) error) error {
+	var w Waybill
+	err := each(s.filteredWaybills(ctx, scope, filter), &w, func() error {
+		defer func() { w = Waybill{} }()
+		return fn(w)
+	})
+	if err != nil {
+		return fmt.Errorf("reading waybills: %w", err)
+	}
+	return nil
+}
+
+func (s *GormStore) filteredWaybills(ctx context.Context, scope Scope, filter WaybillFilter) *gorm.DB {
+	where := s.waybills(ctx, scope)
+	if filter.WaybillNumber != "" {
+		where = where.Where("waybills.waybill_number = ?", filter.WaybillNumber)
+	}
+	if filter.BillOfLadingNumber != "" {
+		where = where.Where("waybills.bill_of_lading_number = ?", filter.BillOfLadingNumber)
+	}
+	if filter.EquipmentID != "" {
+		where = where.Where("waybills.equipment_id = ?", filter.EquipmentID)
+	}
+	if len(filter.IDs) > 0 {
+		where = where.Where("waybills.id IN ?", filter.IDs)
+	}
+	if len(filter.EquipmentIDs) > 0 {
+		where = where.Where("waybills.equipment_id IN ?", filter.EquipmentIDs)
+	}
+	return where.Order("waybills.id")
+}
+
+func (s *GormStore) WaybillByID(ctx context.Context, scope Scope, id string) (Waybill, error) {
+	var waybill Waybill
+	result := s.waybills(ctx, scope).Where("waybills.id = ?", id).First(&waybill)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return Waybill{}, ErrNotFound
+		}
+		return Waybill{}, fmt.Errorf("finding waybill by id: %w", result.Error)
+	}
+	return waybill, nil
+}
+
+// waybills limits waybill queries to the scope's customers' shipments, so
+// other customers' waybills are simply not found.
+func (s *GormStore) waybills(ctx context.Context, scope Scope) *gorm.DB {
+	where := s.db.WithContext(ctx).Model(&Waybill{})
+	if scope.Restricted {
+		where = where.Where("waybills.id IN (?)", scopedWaybillIDs(s.db, scope))
+	}
+	return where
+}
+
+// each scans query's rows into dest one at a time, calling fn after each, so
+// exports don't hold the whole result in memory.
+func each(query *gorm.DB, dest interface{}, fn func() error) error {
+	rows, err := query.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := query.ScanRows(rows, dest); err != nil {
+			return err
+		}
+		if err := fn(); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// scopedWaybillIDs selects the IDs of the scope's customers' waybills: those
+// whose car's record in effect for the shipment, as EquipmentAt picks it,
+// belongs to one of them. A car's records don't overlap, so that's its
+// earliest record not removed by the waybill date and added by the end of
+// the shipment, the waybill date or its last sighting.
+func scopedWaybillIDs(db *gorm.DB, scope Scope) *gorm.DB {
+	return db.Table("waybills AS w").Select("w.id").
+		Joins("JOIN equipment e ON e.equipment_id = w.equipment_id").
+		Where("e.customer IN ?", scope.Customers).
+		Where("e.date_removed > w.waybill_date OR e.date_removed = ?", time.Time{}).
+		Where("e.date_added <= w.waybill_date OR e.date_added <= (SELECT MAX(v.sighting_date) FROM events v WHERE v.waybill_id = w.id)").
+		Where("NOT EXISTS (SELECT 1 FROM equipment p WHERE p.equipment_id = w.equipment_id AND (p.date_removed > w.waybill_date OR p.date_removed = ?) AND p.date_added < e.date_added)", time.Time{})
+}
+
+func (s *GormStore) ActiveAPIKey(ctx context.Context, hash string) (APIKey, error) {
+	var k APIKey
+	result := s.db.WithContext(ctx).Where("hash = ? AND revoked_at IS NULL", hash).First(&k)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return APIKey{}, ErrNotFound
+		}
+		return APIKey{}, fmt.Errorf("finding api key: %w", result.Error)
+	}
+	return k, nil
+}
+
+func (s *GormStore) TouchAPIKey(ctx context.Context, id uint, at time.Time) error {
+	result := s.db.WithContext(ctx).Model(&APIKey{}).Where("id = ?", id).UpdateColumn("last_used_at", at)
+	if result.Error != nil {
+		return fmt.Errorf("recording api key use: %w", result.Error)
+	}
+	return nil
+}
+
+func (s *GormStore) Revision(ctx context.Context, datasets ...string) (Revision, error) {
+	var run IngestRun
+	result := s.db.WithContext(ctx).Where("dataset IN ?", datasets).Order("id DESC").Limit(1).Find(&run)
+	if result.Error != nil {
+		return Revision{}, fmt.Errorf("finding latest ingest run: %w", result.Error)
+	}
+	return Revision{ID: run.ID, ModifiedAt: run.IngestedAt}, nil
+}
@@ -0,0 +1,660 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/location"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"math"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// graphQLListSize is how many items each list field is assumed to hold when
+// estimating a query's complexity.
+const graphQLListSize = 10
+
+// Codes in the extensions of GraphQL errors, alongside the problem codes.
+const (
+	CodeQueryTooDeep    = "query_too_deep"
+	CodeQueryTooComplex = "query_too_complex"
+)
+
+// graphQLRequest is a GraphQL query sent over HTTP.
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// graphQLError is an error reported in a GraphQL response, with a code in its
+// extensions the way problems carry one.
+type graphQLError struct {
+	message   string
+	code      string
+	requestID string
+}
+
+func (e graphQLError) Error() string {
+	return e.message
+}
+
+func (e graphQLError) Extensions() map[string]interface{} {
+	ext := map[string]interface{}{"code": e.code}
+	if e.requestID != "" {
+		ext["request_id"] = e.requestID
+	}
+	return ext
+}
+
+// GraphQL runs GraphQL queries over waybills, equipment, events and
+// locations. Related records are batched: resolving the same relation for
+// many parents reads it with one store call. Queries nesting deeper than
+// the configured depth or estimated to resolve too many fields are rejected
+// before they run.
+func (h *HTTP) GraphQL() gin.HandlerFunc {
+	schema, err := h.graphQLSchema()
+	if err != nil {
+		panic(fmt.Sprintf("building graphql schema: %v", err))
+	}
+
+	return func(c *gin.Context) {
+		var req graphQLRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.problem(c, http.StatusBadRequest, CodeMalformedBody, "The body must be a JSON object with a query.")
+			return
+		}
+		if strings.TrimSpace(req.Query) == "" {
+			h.invalid(c, FieldError{Name: "query", In: "body", Reason: "is required"})
+			return
+		}
+
+		if err := h.checkGraphQLCost(schema, req); err != nil {
+			c.JSON(http.StatusOK, graphql.Result{Errors: []gqlerrors.FormattedError{*err}})
+			return
+		}
+
+		ctx := context.WithValue(c.Request.Context(), graphQLKey{}, h.newGraphQLLoaders(c))
+		c.JSON(http.StatusOK, graphql.Do(graphql.Params{
+			Schema:         schema,
+			RequestString:  req.Query,
+			OperationName:  req.OperationName,
+			VariableValues: req.Variables,
+			Context:        ctx,
+		}))
+	}
+}
+
+// checkGraphQLCost rejects queries over the configured depth or complexity.
+// Queries that don't parse are left for graphql.Do to report.
+func (h *HTTP) checkGraphQLCost(schema graphql.Schema, req graphQLRequest) *gqlerrors.FormattedError {
+	doc, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"})})
+	if err != nil {
+		return nil
+	}
+
+	depth, complexity := graphQLCost(schema, doc, req.OperationName)
+	switch {
+	case depth > h.cfg.GraphQLMaxDepth:
+		return &gqlerrors.FormattedError{
+			Locations:  []location.SourceLocation{},
+			Message:    fmt.Sprintf("The query nests %d fields deep; at most %d are allowed.", depth, h.cfg.GraphQLMaxDepth),
+			Extensions: map[string]interface{}{"code": CodeQueryTooDeep},
+		}
+	case complexity > h.cfg.GraphQLMaxComplexity:
+		return &gqlerrors.FormattedError{
+			Locations: []location.SourceLocation{},
+			Message: fmt.Sprintf("The query has a complexity of %d; at most %d is allowed. Each list field counts as %d items.",
+				complexity, h.cfg.GraphQLMaxComplexity, graphQLListSize),
+			Extensions: map[string]interface{}{"code": CodeQueryTooComplex},
+		}
+	}
+	return nil
+}
+
+// graphQLCost measures the operation a query runs: how deep its fields nest
+// and an estimate of how many fields it resolves, each field counting one
+// and the selections under a list counting graphQLListSize times.
+// Introspection fields are free.
+func graphQLCost(schema graphql.Schema, doc *ast.Document, operationName string) (depth, complexity int) {
+	w := costWalker{schema: schema, fragments: make(map[string]*ast.FragmentDefinition), visiting: make(map[string]bool)}
+	var operations []*ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *ast.FragmentDefinition:
+			w.fragments[def.Name.Value] = def
+		case *ast.OperationDefinition:
+			if operationName == "" || (def.Name != nil && def.Name.Value == operationName) {
+				operations = append(operations, def)
+			}
+		}
+	}
+	if len(operations) != 1 {
+		return 0, 0
+	}
+	return w.selections(operations[0].SelectionSet, schema.QueryType(), 1)
+}
+
+type costWalker struct {
+	schema    graphql.Schema
+	fragments map[string]*ast.FragmentDefinition
+	// visiting guards against fragment cycles, which validation rejects
+	// later.
+	visiting map[string]bool
+}
+
+func (w costWalker) selections(set *ast.SelectionSet, parent graphql.Type, level int) (depth, complexity int) {
+	if set == nil {
+		return 0, 0
+	}
+	add := func(d, c int) {
+		if d > depth {
+			depth = d
+		}
+		complexity = saturatingAdd(complexity, c)
+	}
+
+	for _, sel := range set.Selections {
+		switch sel := sel.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(sel.Name.Value, "__") {
+				continue
+			}
+			d, c := level, 1
+			if sel.SelectionSet != nil {
+				child, list := fieldType(parent, sel.Name.Value)
+				cd, cc := w.selections(sel.SelectionSet, child, level+1)
+				if list {
+					cc = saturatingMul(cc, graphQLListSize)
+				}
+				if cd > d {
+					d = cd
+				}
+				c = saturatingAdd(c, cc)
+			}
+			add(d, c)
+		case *ast.InlineFragment:
+			t := parent
+			if sel.TypeCondition != nil {
+				t = w.schema.Type(sel.TypeCondition.Name.Value)
+			}
+			add(w.selections(sel.SelectionSet, t, level))
+		case *ast.FragmentSpread:
+			name := sel.Name.Value
+			f, ok := w.fragments[name]
+			if !ok || w.visiting[name] {
+				continue
+			}
+			w.visiting[name] = true
+			add(w.selections(f.SelectionSet, w.schema.Type(f.TypeCondition.Name.Value), level))
+			delete(w.visiting, name)
+		}
+	}
+	return depth, complexity
+}
+
+// fieldType returns the object type a field of parent resolves to and
+// whether it's a list. Unknown fields are left for validation to report.
+func fieldType(parent graphql.Type, name string) (graphql.Type, bool) {
+	object, ok := parent.(*graphql.Object)
+	if !ok {
+		return nil, false
+	}
+	field, ok := object.Fields()[name]
+	if !ok {
+		return nil, false
+	}
+
+	t, list := field.Type, false
+	for {
+		switch wrapped := t.(type) {
+		case *graphql.NonNull:
+			t = wrapped.OfType
+		case *graphql.List:
+			t, list = wrapped.OfType, true
+		default:
+			return t, list
+		}
+	}
+}
+
+func saturatingAdd(a, b int) int {
+	if a > math.MaxInt32-b {
+		return math.MaxInt32
+	}
+	return a + b
+}
+
+func saturatingMul(a, b int) int {
+	if a > math.MaxInt32/b {
+		return math.MaxInt32
+	}
+	return a * b
+}
+
+// graphQLSchema builds the schema. Its object types mirror the models' JSON
+// fields, plus fields for their relationships.
+func (h *HTTP) graphQLSchema() (graphql.Schema, error) {
+	routePart := graphql.NewObject(graphql.ObjectConfig{
+		Name: "RoutePart", Description: schemaDescriptions["RoutePart"], Fields: graphQLFields(reflect.TypeOf(RoutePart{})),
+	})
+	party := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Party", Description: schemaDescriptions["Party"], Fields: graphQLFields(reflect.TypeOf(Party{})),
+	})
+	location := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Location", Description: schemaDescriptions["Location"], Fields: graphQLFields(reflect.TypeOf(Location{})),
+	})
+	equipment := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Equipment", Description: schemaDescriptions["Equipment"], Fields: graphQLFields(reflect.TypeOf(Equipment{})),
+	})
+	event := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Event", Description: schemaDescriptions["Event"], Fields: graphQLFields(reflect.TypeOf(Event{})),
+	})
+	waybill := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Waybill", Description: "A shipment of a car from an origin to a destination.",
+		Fields: graphQLFields(reflect.TypeOf(Waybill{}), "routes", "parties"),
+	})
+
+	afterArg := graphql.FieldConfigArgument{
+		"after": {Type: graphql.DateTime, Description: "Only events posted after this time."},
+	}
+	listOf := func(t graphql.Type) graphql.Output {
+		return graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(t)))
+	}
+
+	waybill.AddFieldConfig("routes", &graphql.Field{
+		Type: listOf(routePart), Description: "The railroads the car is routed over, in order.",
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(Waybill).DecodeRoutes()
+		},
+	})
+	waybill.AddFieldConfig("parties", &graphql.Field{
+		Type: listOf(party), Description: "The parties to the waybill.",
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(Waybill).DecodeParties()
+		},
+	})
+	waybill.AddFieldConfig("equipment", &graphql.Field{
+		Type: equipment, Description: "The fleet record in effect for the waybill, as /waybills/:id/equipment picks it.",
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			w := p.Source.(Waybill)
+			return graphQLLoadersFrom(p.Context).waybillEquipment.load(w.ID, w), nil
+		},
+	})
+	waybill.AddFieldConfig("events", &graphql.Field{
+		Type: listOf(event), Description: "The waybill's events, ordered by sighting date.", Args: afterArg,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			after, _ := p.Args["after"].(time.Time)
+			return graphQLLoadersFrom(p.Context).waybillEvents(after).load(p.Source.(Waybill).ID, nil), nil
+		},
+	})
+	waybill.AddFieldConfig("origin", &graphql.Field{
+		Type: location, Description: "Where the shipment starts.",
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return graphQLLoadersFrom(p.Context).locations.load(p.Source.(Waybill).OriginID, nil), nil
+		},
+	})
+	waybill.AddFieldConfig("destination", &graphql.Field{
+		Type: location, Description: "Where the shipment ends.",
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return graphQLLoadersFrom(p.Context).locations.load(p.Source.(Waybill).DestinationID, nil), nil
+		},
+	})
+	event.AddFieldConfig("waybill", &graphql.Field{
+		Type: waybill, Description: "The waybill the car was moving under.",
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return graphQLLoadersFrom(p.Context).waybills.load(p.Source.(Event).WaybillID, nil), nil
+		},
+	})
+	event.AddFieldConfig("location", &graphql.Field{
+		Type: location, Description: "Where the car was sighted.",
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return graphQLLoadersFrom(p.Context).locations.load(p.Source.(Event).LocationID, nil), nil
+		},
+	})
+	equipment.AddFieldConfig("waybills", &graphql.Field{
+		Type: listOf(waybill), Description: "The car's waybills, newest first.",
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return graphQLLoadersFrom(p.Context).carWaybills.load(p.Source.(Equipment).EquipmentID, nil), nil
+		},
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"waybill": &graphql.Field{
+				Type: waybill, Description: "A waybill by ID, or null when it doesn't exist or isn't visible.",
+				Args: graphql.FieldConfigArgument{"id": {Type: graphql.NewNonNull(graphql.ID)}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					l := graphQLLoadersFrom(p.Context)
+					w, err := h.waybills.WaybillByID(p.Context, l.scope, p.Args["id"].(string))
+					if errors.Is(err, ErrNotFound) {
+						return nil, nil
+					}
+					if err != nil {
+						return nil, l.internal(fmt.Errorf("finding waybill: %w", err))
+					}
+					return w, nil
+				},
+			},
+			"waybills": &graphql.Field{
+				Type: listOf(waybill), Description: "Waybills, optionally matching a reference customers quote.",
+				Args: graphql.FieldConfigArgument{
+					"waybill_number":        {Type: graphql.String},
+					"bill_of_lading_number": {Type: graphql.String},
+					"equipment_id":          {Type: graphql.String},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					l := graphQLLoadersFrom(p.Context)
+					number, _ := p.Args["waybill_number"].(string)
+					bol, _ := p.Args["bill_of_lading_number"].(string)
+					car, _ := p.Args["equipment_id"].(string)
+					waybills, err := h.waybills.ListWaybills(p.Context, l.scope, WaybillFilter{WaybillNumber: number, BillOfLadingNumber: bol, EquipmentID: car})
+					if err != nil {
+						return nil, l.internal(fmt.Errorf("finding waybills: %w", err))
+					}
+					return waybills, nil
+				},
+			},
+			"equipment": &graphql.Field{
+				Type: listOf(equipment), Description: "Fleet membership records.",
+				Args: graphql.FieldConfigArgument{"as_of": {Type: graphql.DateTime, Description: "List the fleet as it was at this time."}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					l := graphQLLoadersFrom(p.Context)
+					asOf, _ := p.Args["as_of"].(time.Time)
+					records, err := h.equipment.ListEquipment(p.Context, l.scope, EquipmentFilter{AsOf: asOf})
+					if err != nil {
+						return nil, l.internal(fmt.Errorf("finding equipment: %w", err))
+					}
+					return records, nil
+				},
+			},
+			"events": &graphql.Field{
+				Type: listOf(event), Description: "Events, ordered by sighting date.", Args: afterArg,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					l := graphQLLoadersFrom(p.Context)
+					after, _ := p.Args["after"].(time.Time)
+					events, err := h.events.ListEvents(p.Context, l.scope, EventFilter{PostedAfter: after})
+					if err != nil {
+						return nil, l.internal(fmt.Errorf("finding events: %w", err))
+					}
+					return events, nil
+				},
+			},
+			"locations": &graphql.Field{
+				Type: listOf(location), Description: "Every location.",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					locations, err := h.locations.ListLocations(p.Context)
+					if err != nil {
+						return nil, graphQLLoadersFrom(p.Context).internal(fmt.Errorf("finding locations: %w", err))
+					}
+					return locations, nil
+				},
+			},
+			"location": &graphql.Field{
+				Type: location, Description: "A location by ID, or null when it doesn't exist.",
+				Args: graphql.FieldConfigArgument{"id": {Type: graphql.NewNonNull(graphql.ID)}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return graphQLLoadersFrom(p.Context).locations.load(p.Args["id"].(string), nil), nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query})
+}
+
+// graphQLFields mirrors a model's JSON fields, so GraphQL and REST name them
+// alike. Fields left out of the JSON when empty are nullable and resolve to
+// null when zero.
+func graphQLFields(t reflect.Type, skip ...string) graphql.Fields {
+	fields := graphql.Fields{}
+	for k := 0; k < t.NumField(); k++ {
+		f := t.Field(k)
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if !f.IsExported() || name == "" || name == "-" || contains(skip, name) {
+			continue
+		}
+
+		var typ graphql.Output
+		switch {
+		case name == "id":
+			typ = graphql.ID
+		case f.Type == reflect.TypeOf(time.Time{}):
+			typ = graphql.DateTime
+		case f.Type.Kind() == reflect.String:
+			typ = graphql.String
+		case f.Type.Kind() == reflect.Int || f.Type.Kind() == reflect.Int64:
+			typ = graphql.Int
+		case f.Type.Kind() == reflect.Float64:
+			typ = graphql.Float
+		default:
+			panic(fmt.Sprintf("no graphql type for %s.%s", t.Name(), f.Name))
+		}
+
+		omitEmpty := strings.Contains(opts, "omitempty")
+		if !omitEmpty {
+			typ = graphql.NewNonNull(typ)
+		}
+		index := f.Index
+		fields[name] = &graphql.Field{
+			Type: typ,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				v := reflect.ValueOf(p.Source).FieldByIndex(index)
+				if omitEmpty && isZeroValue(v) {
+					return nil, nil
+				}
+				return v.Interface(), nil
+			},
+		}
+	}
+	return fields
+}
+
+// isZeroValue reports whether v is its type's zero value. Times count as
+// zero by instant, since the database reads them back with a location.
+func isZeroValue(v reflect.Value) bool {
+	if t, ok := v.Interface().(time.Time); ok {
+		return t.IsZero()
+	}
+	return v.IsZero()
+}
+
+type graphQLKey struct{}
+
+// graphQLLoaders batches the relations resolved during one query. Each loader
+// collects the keys asked for while a level of the query resolves, and
+// fetches them all when the first result is needed.
+type graphQLLoaders struct {
+	h         *HTTP
+	ctx       context.Context
+	scope     Scope
+	requestID string
+	internal  func(error) error
+
+	locations        *loader
+	waybills         *loader
+	carWaybills      *loader
+	waybillEquipment *loader
+
+	mu           sync.Mutex
+	eventsByTime map[time.Time]*loader
+}
+
+func graphQLLoadersFrom(ctx context.Context) *graphQLLoaders {
+	return ctx.Value(graphQLKey{}).(*graphQLLoaders)
+}
+
+func (h *HTTP) newGraphQLLoaders(c *gin.Context) *graphQLLoaders {
+	ctx := c.Request.Context()
+	l := &graphQLLoaders{h: h, ctx: ctx, scope: customerScope(c), requestID: c.GetString(requestIDKey), eventsByTime: make(map[time.Time]*loader)}
+
+	// Store errors are logged against the request ID and reported without
+	// details, as internalError does.
+	l.internal = func(err error) error {
+		h.log.Sugar().Errorw(err.Error(), "request_id", l.requestID, "path", c.Request.URL.Path)
+		return graphQLError{message: "The server could not complete the request.", code: CodeInternal, requestID: l.requestID}
+	}
+
+	l.locations = newLoader(l.internal, func(ids []string, _ []interface{}) (map[string]interface{}, error) {
+		locations, err := h.locations.LocationsByID(ctx, ids)
+		if err != nil {
+			return nil, fmt.Errorf("finding locations: %w", err)
+		}
+		found := make(map[string]interface{})
+		for _, loc := range locations {
+			found[loc.ID] = loc
+		}
+		return found, nil
+	})
+
+	l.waybills = newLoader(l.internal, func(ids []string, _ []interface{}) (map[string]interface{}, error) {
+		waybills, err := h.waybills.ListWaybills(ctx, l.scope, WaybillFilter{IDs: ids})
+		if err != nil {
+			return nil, fmt.Errorf("finding waybills: %w", err)
+		}
+		found := make(map[string]interface{})
+		for _, w := range waybills {
+			found[w.ID] = w
+		}
+		return found, nil
+	})
+
+	l.carWaybills = newLoader(l.internal, func(cars []string, _ []interface{}) (map[string]interface{}, error) {
+		waybills, err := h.waybills.ListWaybills(ctx, l.scope, WaybillFilter{EquipmentIDs: cars})
+		if err != nil {
+			return nil, fmt.Errorf("finding waybills of cars: %w", err)
+		}
+		sort.SliceStable(waybills, func(i, j int) bool {
+			if !waybills[i].WaybillDate.Equal(waybills[j].WaybillDate) {
+				return waybills[i].WaybillDate.After(waybills[j].WaybillDate)
+			}
+			return waybills[i].ID < waybills[j].ID
+		})
+		byCar := make(map[string][]Waybill)
+		for _, w := range waybills {
+			byCar[w.EquipmentID] = append(byCar[w.EquipmentID], w)
+		}
+		found := make(map[string]interface{})
+		for _, car := range cars {
+			found[car] = append([]Waybill{}, byCar[car]...)
+		}
+		return found, nil
+	})
+
+	// The equipment in effect is picked as ?include=equipment does, which
+	// reads the records and sightings of all the waybills at once.
+	l.waybillEquipment = newLoader(l.internal, func(ids []string, sources []interface{}) (map[string]interface{}, error) {
+		waybills := make([]Waybill, len(sources))
+		for k, s := range sources {
+			waybills[k] = s.(Waybill)
+		}
+		details, err := h.waybillDetails(ctx, l.scope, waybills, map[string]bool{IncludeEquipment: true}, LatestAPIVersion)
+		if err != nil {
+			return nil, err
+		}
+		found := make(map[string]interface{})
+		for _, d := range details {
+			if equipment := *d.Included.Equipment; len(equipment) > 0 {
+				found[d.ID] = equipment[0]
+			}
+		}
+		return found, nil
+	})
+
+	return l
+}
+
+// waybillEvents returns the loader for waybills' events posted after a time.
+func (l *graphQLLoaders) waybillEvents(after time.Time) *loader {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if events, ok := l.eventsByTime[after]; ok {
+		return events
+	}
+	events := newLoader(l.internal, func(ids []string, _ []interface{}) (map[string]interface{}, error) {
+		// Waybills are only loaded within the scope, so their events are
+		// read unscoped as /waybills/:id/events does.
+		events, err := l.h.events.ListEvents(l.ctx, Scope{}, EventFilter{WaybillIDs: ids, PostedAfter: after})
+		if err != nil {
+			return nil, fmt.Errorf("finding waybill events: %w", err)
+		}
+		byWaybill := make(map[string][]Event)
+		for _, e := range events {
+			byWaybill[e.WaybillID] = append(byWaybill[e.WaybillID], e)
+		}
+		found := make(map[string]interface{})
+		for _, id := range ids {
+			found[id] = append([]Event{}, byWaybill[id]...)
+		}
+		return found, nil
+	})
+	l.eventsByTime[after] = events
+	return events
+}
+
+// loader is a minimal dataloader. load queues a key and returns a thunk,
+// which graphql-go calls once every field at the same level has been
+// resolved; the first thunk called fetches every queued key at once.
+type loader struct {
+	fetch    func(keys []string, sources []interface{}) (map[string]interface{}, error)
+	internal func(error) error
+
+	mu      sync.Mutex
+	keys    []string
+	sources []interface{}
+	queued  map[string]bool
+	results map[string]interface{}
+	fetched map[string]bool
+	err     error
+}
+
+func newLoader(internal func(error) error, fetch func(keys []string, sources []interface{}) (map[string]interface{}, error)) *loader {
+	return &loader{fetch: fetch, internal: internal, queued: make(map[string]bool), results: make(map[string]interface{}), fetched: make(map[string]bool)}
+}
+
+// load queues key, along with the value it was taken from for fetches that
+// need more than the key.
+func (l *loader) load(key string, source interface{}) func() (interface{}, error) {
+	l.mu.Lock()
+	if !l.fetched[key] && !l.queued[key] {
+		l.keys = append(l.keys, key)
+		l.sources = append(l.sources, source)
+		l.queued[key] = true
+	}
+	l.mu.Unlock()
+
+	return func() (interface{}, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if !l.fetched[key] && l.err == nil {
+			results, err := l.fetch(l.keys, l.sources)
+			if err != nil {
+				l.err = l.internal(err)
+			}
+			for k, v := range results {
+				l.results[k] = v
+			}
+			for _, k := range l.keys {
+				l.fetched[k] = true
+			}
+			l.keys, l.sources, l.queued = nil, nil, make(map[string]bool)
+		}
+		if l.err != nil {
+			return nil, l.err
+		}
+		return l.results[key], nil
+	}
+}
@@ -0,0 +1,114 @@
+package app_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/coreyvan/backend-takehome/internal/app"
+	"github.com/coreyvan/backend-takehome/internal/config"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// countingStore counts the reads that go through it.
+type countingStore struct {
+	*app.MemoryStore
+	calls map[string]int
+}
+
+func (s *countingStore) ListWaybills(ctx context.Context, scope app.Scope, filter app.WaybillFilter) ([]app.Waybill, error) {
+	s.calls["ListWaybills"]++
+	return s.MemoryStore.ListWaybills(ctx, scope, filter)
+}
+
+func (s *countingStore) ListEvents(ctx context.Context, scope app.Scope, filter app.EventFilter) ([]app.Event, error) {
+	s.calls["ListEvents"]++
+	return s.MemoryStore.ListEvents(ctx, scope, filter)
+}
+
+func (s *countingStore) ListEquipment(ctx context.Context, scope app.Scope, filter app.EquipmentFilter) ([]app.Equipment, error) {
+	s.calls["ListEquipment"]++
+	return s.MemoryStore.ListEquipment(ctx, scope, filter)
+}
+
+func (s *countingStore) LocationsByID(ctx context.Context, ids []string) ([]app.Location, error) {
+	s.calls["LocationsByID"]++
+	return s.MemoryStore.LocationsByID(ctx, ids)
+}
+
+// TestGraphQLBatchesRelations checks each relation is read once per level of
+// a query however many parents it's resolved for.
+func TestGraphQLBatchesRelations(t *testing.T) {
+	day := time.Date(2021, 8, 1, 0, 0, 0, 0, time.UTC)
+	var (
+		locations []app.Location
+		equipment []app.Equipment
+		waybills  []app.Waybill
+		events    []app.Event
+	)
+	for k := 1; k <= 5; k++ {
+		id := strconv.Itoa(k)
+		car := "CAR" + id
+		locations = append(locations, app.Location{ID: id, Station: "STATION" + id})
+		equipment = append(equipment, app.Equipment{ID: id, Customer: "TELGRAPH", EquipmentID: car, DateAdded: day})
+		waybills = append(waybills, app.Waybill{ID: id, EquipmentID: car, WaybillDate: day, OriginID: id, DestinationID: "1"})
+		for e := 0; e < 3; e++ {
+			events = append(events, app.Event{
+				ID: id + strconv.Itoa(e), EquipmentID: car, WaybillID: id, LocationID: strconv.Itoa(e + 1),
+				SightingDate: day.Add(time.Duration(e) * time.Hour), PostingDate: day.Add(time.Duration(e) * time.Hour),
+			})
+		}
+	}
+
+	mem := app.NewMemoryStore(locations, equipment, waybills, events)
+	store := &countingStore{MemoryStore: mem, calls: make(map[string]int)}
+	key, _, err := mem.CreateAPIKey("test", []string{app.AllCustomers})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stores := app.Stores{Waybills: store, Events: store, Equipment: store, Locations: store, Keys: mem, Revisions: mem}
+	h := app.NewHTTP(zap.NewNop(), config.Default().HTTP, stores, nil)
+
+	query := `{"query": "{ waybills { id origin { station } equipment { customer } events { id location { station } waybill { id } } } }"}`
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(query))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", key)
+	rec := httptest.NewRecorder()
+	h.Handler().ServeHTTP(rec, req)
+
+	var res struct {
+		Data struct {
+			Waybills []struct {
+				Events []struct {
+					Location *struct{ Station string }
+				}
+			}
+		}
+		Errors []json.RawMessage
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+		t.Fatalf("%d %s: %v", rec.Code, rec.Body, err)
+	}
+	if len(res.Errors) > 0 || len(res.Data.Waybills) != 5 {
+		t.Fatalf("unexpected response: %s", rec.Body)
+	}
+	for _, w := range res.Data.Waybills {
+		if len(w.Events) != 3 || w.Events[2].Location == nil || w.Events[2].Location.Station != "STATION3" {
+			t.Fatalf("unexpected events: %s", rec.Body)
+		}
+	}
+
+	// waybills lists once and event waybills load once. Events are read for
+	// the events field and again for the equipment in effect. Origins and
+	// event locations load together when the origins are resolved last.
+	limits := map[string]int{"ListWaybills": 2, "ListEvents": 2, "ListEquipment": 1, "LocationsByID": 2}
+	for call, n := range limits {
+		if store.calls[call] > n {
+			t.Errorf("%s called %d times, want at most %d", call, store.calls[call], n)
+		}
+	}
+}
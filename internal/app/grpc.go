@@ -0,0 +1,517 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	telegraphv1 "github.com/coreyvan/backend-takehome/proto/telegraph/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"net"
+	"strings"
+	"time"
+)
+
+// grpcErrorDomain is the domain of the ErrorInfo details gRPC errors carry.
+const grpcErrorDomain = "telegraph"
+
+// grpcCallKey is the context key of the caller's grpcCall.
+type grpcCallKey struct{}
+
+// grpcCall is what the interceptors learn about a call.
+type grpcCall struct {
+	scope     Scope
+	requestID string
+}
+
+func grpcCallFrom(ctx context.Context) grpcCall {
+	call, _ := ctx.Value(grpcCallKey{}).(grpcCall)
+	return call
+}
+
+// GRPC serves the Telegraph gRPC service, which mirrors the HTTP API's
+// tracking routes for internal services, from the same stores.
+type GRPC struct {
+	telegraphv1.UnimplementedTelegraphServer
+
+	h    *HTTP
+	port string
+	srv  *grpc.Server
+}
+
+// NewGRPC serves the data h serves over gRPC on port.
+func NewGRPC(h *HTTP, port string) *GRPC {
+	s := &GRPC{h: h, port: port}
+	s.srv = grpc.NewServer(
+		grpc.ChainUnaryInterceptor(s.unaryInterceptor),
+		grpc.ChainStreamInterceptor(s.streamInterceptor),
+	)
+	telegraphv1.RegisterTelegraphServer(s.srv, s)
+	return s
+}
+
+func (s *GRPC) Listen() error {
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%s", s.port))
+	if err != nil {
+		return fmt.Errorf("listening for grpc: %w", err)
+	}
+	return s.Serve(lis)
+}
+
+// Serve accepts gRPC connections on lis until it's closed.
+func (s *GRPC) Serve(lis net.Listener) error {
+	return s.srv.Serve(lis)
+}
+
+// Shutdown stops accepting calls and waits for running ones to finish until
+// ctx is done, then cancels them. Streams end once the HTTP server starts
+// shutting down.
+func (s *GRPC) Shutdown(ctx context.Context) {
+	done := make(chan struct{})
+	go func() {
+		s.srv.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.srv.Stop()
+		<-done
+	}
+}
+
+func (s *GRPC) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (res interface{}, err error) {
+	start := time.Now()
+	ctx, err = s.authenticate(ctx)
+	if err == nil {
+		defer s.recovered(ctx, info.FullMethod, &err)
+		res, err = handler(ctx, req)
+	}
+	s.logCall(ctx, info.FullMethod, start, err)
+	return res, err
+}
+
+func (s *GRPC) streamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	start := time.Now()
+	ctx, err := s.authenticate(stream.Context())
+	if err == nil {
+		defer s.recovered(ctx, info.FullMethod, &err)
+		err = handler(srv, callStream{ServerStream: stream, ctx: ctx})
+	}
+	s.logCall(ctx, info.FullMethod, start, err)
+	return err
+}
+
+// callStream carries the grpcCall in a stream's context.
+type callStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s callStream) Context() context.Context {
+	return s.ctx
+}
+
+// authenticate requires a valid API key in the x-api-key metadata or as a
+// bearer token in authorization, as the HTTP API does, and tags the call with
+// the key's scope and a request ID.
+func (s *GRPC) authenticate(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	first := func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+
+	call := grpcCall{requestID: ensureRequestID(first(strings.ToLower(RequestIDHeader)))}
+	ctx = context.WithValue(ctx, grpcCallKey{}, call)
+	_ = grpc.SetHeader(ctx, metadata.Pairs(strings.ToLower(RequestIDHeader), call.requestID))
+
+	key := first("x-api-key")
+	if key == "" {
+		key = strings.TrimPrefix(first("authorization"), "Bearer ")
+	}
+	if key == "" || !strings.HasPrefix(key, apiKeyPrefix) {
+		return ctx, grpcError(ctx, codes.Unauthenticated, CodeAPIKeyRequired, "Send an API key in the x-api-key metadata or as a bearer token.")
+	}
+
+	k, err := s.h.activeAPIKey(ctx, key)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return ctx, grpcError(ctx, codes.Unauthenticated, CodeAPIKeyInvalid, "The API key is unknown or has been revoked.")
+		}
+		return ctx, s.internal(ctx, err)
+	}
+
+	call.scope = scopeFor(strings.Split(k.Customers, ","))
+	return context.WithValue(ctx, grpcCallKey{}, call), nil
+}
+
+// recovered turns a panicking call into an internal error, as the HTTP API's
+// recovery middleware does.
+func (s *GRPC) recovered(ctx context.Context, method string, err *error) {
+	if r := recover(); r != nil {
+		*err = s.internal(ctx, fmt.Errorf("panic in %s: %v", method, r))
+	}
+}
+
+func (s *GRPC) logCall(ctx context.Context, method string, start time.Time, err error) {
+	s.h.log.Sugar().Infow("grpc call", "method", method, "code", status.Code(err).String(),
+		"duration", time.Since(start), "request_id", grpcCallFrom(ctx).requestID)
+}
+
+// grpcError is a gRPC status with the problem code the HTTP API would send
+// as the reason of an ErrorInfo detail.
+func grpcError(ctx context.Context, c codes.Code, code, message string, details ...*errdetails.BadRequest_FieldViolation) error {
+	st := status.New(c, message)
+	info := &errdetails.ErrorInfo{
+		Reason:   code,
+		Domain:   grpcErrorDomain,
+		Metadata: map[string]string{"request_id": grpcCallFrom(ctx).requestID},
+	}
+	withInfo, err := st.WithDetails(info)
+	if err != nil {
+		return st.Err()
+	}
+	if len(details) > 0 {
+		if withViolations, err := withInfo.WithDetails(&errdetails.BadRequest{FieldViolations: details}); err == nil {
+			return withViolations.Err()
+		}
+	}
+	return withInfo.Err()
+}
+
+// invalidGRPC reports that request fields failed validation.
+func invalidGRPC(ctx context.Context, errs ...FieldError) error {
+	violations := make([]*errdetails.BadRequest_FieldViolation, len(errs))
+	for k, e := range errs {
+		violations[k] = &errdetails.BadRequest_FieldViolation{Field: e.Name, Description: e.Reason}
+	}
+	return grpcError(ctx, codes.InvalidArgument, CodeInvalidRequest, "The request has invalid fields.", violations...)
+}
+
+// internal logs err against the request ID and reports it without leaking it.
+func (s *GRPC) internal(ctx context.Context, err error) error {
+	s.h.log.Sugar().Errorw(err.Error(), "request_id", grpcCallFrom(ctx).requestID)
+	return grpcError(ctx, codes.Internal, CodeInternal, "The server could not complete the request.")
+}
+
+func (s *GRPC) ListEquipment(ctx context.Context, req *telegraphv1.ListEquipmentRequest) (*telegraphv1.ListEquipmentResponse, error) {
+	var errs []FieldError
+	filter := EquipmentFilter{AsOf: requestTime(req.AsOf, "as_of", &errs)}
+	if len(errs) > 0 {
+		return nil, invalidGRPC(ctx, errs...)
+	}
+
+	equipment, err := s.h.equipment.ListEquipment(ctx, grpcCallFrom(ctx).scope, filter)
+	if err != nil {
+		return nil, s.internal(ctx, fmt.Errorf("finding all equipment: %w", err))
+	}
+	return &telegraphv1.ListEquipmentResponse{Equipment: protoEquipment(equipment)}, nil
+}
+
+func (s *GRPC) ListEvents(ctx context.Context, req *telegraphv1.ListEventsRequest) (*telegraphv1.ListEventsResponse, error) {
+	var errs []FieldError
+	filter := EventFilter{PostedAfter: requestTime(req.After, "after", &errs)}
+	if len(errs) > 0 {
+		return nil, invalidGRPC(ctx, errs...)
+	}
+
+	events, err := s.h.events.ListEvents(ctx, grpcCallFrom(ctx).scope, filter)
+	if err != nil {
+		return nil, s.internal(ctx, fmt.Errorf("finding events: %w", err))
+	}
+	return &telegraphv1.ListEventsResponse{Events: protoEvents(events)}, nil
+}
+
+func (s *GRPC) ListLocations(ctx context.Context, req *telegraphv1.ListLocationsRequest) (*telegraphv1.ListLocationsResponse, error) {
+	locations, err := s.h.locations.ListLocations(ctx)
+	if err != nil {
+		return nil, s.internal(ctx, fmt.Errorf("finding all locations: %w", err))
+	}
+	return &telegraphv1.ListLocationsResponse{Locations: protoLocations(locations)}, nil
+}
+
+func (s *GRPC) ListWaybills(ctx context.Context, req *telegraphv1.ListWaybillsRequest) (*telegraphv1.ListWaybillsResponse, error) {
+	scope := grpcCallFrom(ctx).scope
+	waybills, err := s.h.waybills.ListWaybills(ctx, scope, WaybillFilter{
+		WaybillNumber:      req.WaybillNumber,
+		BillOfLadingNumber: req.BillOfLadingNumber,
+		EquipmentID:        req.EquipmentId,
+	})
+	if err != nil {
+		return nil, s.internal(ctx, fmt.Errorf("finding all waybills: %w", err))
+	}
+
+	details, err := s.h.waybillDetails(ctx, scope, waybills, nil, LatestAPIVersion)
+	if err != nil {
+		return nil, s.internal(ctx, err)
+	}
+	res := &telegraphv1.ListWaybillsResponse{Waybills: make([]*telegraphv1.Waybill, len(details))}
+	for k, d := range details {
+		res.Waybills[k] = protoWaybill(d)
+	}
+	return res, nil
+}
+
+func (s *GRPC) GetWaybill(ctx context.Context, req *telegraphv1.GetWaybillRequest) (*telegraphv1.Waybill, error) {
+	details, err := s.waybillDetails(ctx, req.Id, nil)
+	if err != nil {
+		return nil, err
+	}
+	return protoWaybill(details), nil
+}
+
+func (s *GRPC) GetWaybillEquipment(ctx context.Context, req *telegraphv1.GetWaybillRequest) (*telegraphv1.ListEquipmentResponse, error) {
+	details, err := s.waybillDetails(ctx, req.Id, map[string]bool{IncludeEquipment: true})
+	if err != nil {
+		return nil, err
+	}
+	return &telegraphv1.ListEquipmentResponse{Equipment: protoEquipment(*details.Included.Equipment)}, nil
+}
+
+func (s *GRPC) ListWaybillEvents(ctx context.Context, req *telegraphv1.ListWaybillEventsRequest) (*telegraphv1.ListEventsResponse, error) {
+	var errs []FieldError
+	after := requestTime(req.After, "after", &errs)
+	if len(errs) > 0 {
+		return nil, invalidGRPC(ctx, errs...)
+	}
+
+	waybill, err := s.findWaybill(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := s.h.events.ListEvents(ctx, Scope{}, EventFilter{WaybillID: waybill.ID, PostedAfter: after})
+	if err != nil {
+		return nil, s.internal(ctx, fmt.Errorf("finding waybill events: %w", err))
+	}
+	return &telegraphv1.ListEventsResponse{Events: protoEvents(events)}, nil
+}
+
+func (s *GRPC) GetWaybillLocations(ctx context.Context, req *telegraphv1.GetWaybillRequest) (*telegraphv1.ListLocationsResponse, error) {
+	waybill, err := s.findWaybill(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	locations, err := s.h.locations.LocationsByID(ctx, []string{waybill.OriginID, waybill.DestinationID})
+	if err != nil {
+		return nil, s.internal(ctx, fmt.Errorf("finding waybill locations: %w", err))
+	}
+	return &telegraphv1.ListLocationsResponse{Locations: protoLocations(locations)}, nil
+}
+
+// StreamEvents sends events posted after the request's cursor, polling the
+// store for new rows as the HTTP event streams do.
+func (s *GRPC) StreamEvents(req *telegraphv1.StreamEventsRequest, stream telegraphv1.Telegraph_StreamEventsServer) error {
+	ctx := stream.Context()
+
+	var cursor EventCursor
+	var errs []FieldError
+	if req.Cursor != "" {
+		parsed, err := parseEventCursor(req.Cursor)
+		if err != nil {
+			errs = append(errs, FieldError{Name: "cursor", In: "body", Reason: "must be a cursor sent on this stream"})
+		}
+		cursor = parsed
+	} else {
+		cursor = EventCursor{PostingDate: requestTime(req.After, "after", &errs)}
+	}
+	if len(errs) > 0 {
+		return invalidGRPC(ctx, errs...)
+	}
+
+	filter := EventFilter{}
+	if req.WaybillId != "" {
+		if _, err := s.findWaybill(ctx, req.WaybillId); err != nil {
+			return err
+		}
+		filter.WaybillID = req.WaybillId
+	}
+
+	poller, err := s.h.newEventPoller(ctx, grpcCallFrom(ctx).scope, filter, cursor)
+	if err != nil {
+		return s.internal(ctx, err)
+	}
+	defer poller.Stop()
+
+	for {
+		events, err := poller.next(ctx)
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case errors.Is(err, errStreamDraining):
+			return grpcError(ctx, codes.Unavailable, CodeShuttingDown, "The server is shutting down. Reconnect with the last cursor to resume the stream.")
+		case err != nil:
+			return s.internal(ctx, err)
+		}
+
+		for _, e := range events {
+			cursor := EventCursor{PostingDate: e.PostingDate, ID: e.ID}
+			if err := stream.Send(&telegraphv1.StreamedEvent{Event: protoEvent(e), Cursor: cursor.String()}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// findWaybill loads a waybill the caller may see.
+func (s *GRPC) findWaybill(ctx context.Context, id string) (Waybill, error) {
+	if id == "" {
+		return Waybill{}, invalidGRPC(ctx, FieldError{Name: "id", In: "body", Reason: "is required"})
+	}
+
+	waybill, err := s.h.waybills.WaybillByID(ctx, grpcCallFrom(ctx).scope, id)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return Waybill{}, grpcError(ctx, codes.NotFound, CodeWaybillNotFound, fmt.Sprintf("Waybill %s was not found.", id))
+		}
+		return Waybill{}, s.internal(ctx, fmt.Errorf("finding waybill by id: %w", err))
+	}
+	return waybill, nil
+}
+
+// waybillDetails loads a waybill the caller may see with the included
+// resources.
+func (s *GRPC) waybillDetails(ctx context.Context, id string, include map[string]bool) (WaybillDetail, error) {
+	waybill, err := s.findWaybill(ctx, id)
+	if err != nil {
+		return WaybillDetail{}, err
+	}
+
+	details, err := s.h.waybillDetails(ctx, grpcCallFrom(ctx).scope, []Waybill{waybill}, include, LatestAPIVersion)
+	if err != nil {
+		return WaybillDetail{}, s.internal(ctx, fmt.Errorf("including waybill resources: %w", err))
+	}
+	return details[0], nil
+}
+
+// requestTime reads an optional time from a request, recording an error when
+// it's out of range.
+func requestTime(ts *timestamppb.Timestamp, name string, errs *[]FieldError) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	if err := ts.CheckValid(); err != nil {
+		*errs = append(*errs, FieldError{Name: name, In: "body", Reason: "must be a valid timestamp"})
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
+
+// protoTime leaves zero times unset.
+func protoTime(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func protoEquipment(equipment []Equipment) []*telegraphv1.Equipment {
+	res := make([]*telegraphv1.Equipment, len(equipment))
+	for k, e := range equipment {
+		res[k] = &telegraphv1.Equipment{
+			Id:              e.ID,
+			Customer:        e.Customer,
+			Fleet:           e.Fleet,
+			EquipmentId:     e.EquipmentID,
+			EquipmentStatus: e.EquipmentStatus,
+			DateAdded:       protoTime(e.DateAdded),
+			DateRemoved:     protoTime(e.DateRemoved),
+		}
+	}
+	return res
+}
+
+func protoLocations(locations []Location) []*telegraphv1.Location {
+	res := make([]*telegraphv1.Location, len(locations))
+	for k, l := range locations {
+		res[k] = &telegraphv1.Location{
+			Id:        l.ID,
+			City:      l.City,
+			CityLong:  l.CityLong,
+			Station:   l.Station,
+			Fsac:      l.FSAC,
+			Scac:      l.SCAC,
+			Splc:      l.SPLC,
+			State:     l.State,
+			TimeZone:  l.Timezone,
+			Longitude: l.Longitude,
+			Latitude:  l.Latitude,
+			Country:   l.Country,
+		}
+	}
+	return res
+}
+
+func protoEvents(events []Event) []*telegraphv1.Event {
+	res := make([]*telegraphv1.Event, len(events))
+	for k, e := range events {
+		res[k] = protoEvent(e)
+	}
+	return res
+}
+
+func protoEvent(e Event) *telegraphv1.Event {
+	return &telegraphv1.Event{
+		Id:                    e.ID,
+		EquipmentId:           e.EquipmentID,
+		SightingDate:          protoTime(e.SightingDate),
+		SightingEventCode:     e.SightingEventCode,
+		ReportingRailroadScac: e.ReportingRailroadSCAC,
+		PostingDate:           protoTime(e.PostingDate),
+		FromMarkId:            e.FromMarkID,
+		LoadEmptyStatus:       e.LoadEmptyStatus,
+		SightingClaimCode:     e.SightingClaimCode,
+		SightingEventCodeText: e.SightingEventCodeText,
+		TrainId:               e.TrainID,
+		TrainAlphaCode:        e.TrainAlphaCode,
+		LocationId:            e.LocationID,
+		WaybillId:             e.WaybillID,
+	}
+}
+
+func protoWaybill(d WaybillDetail) *telegraphv1.Waybill {
+	w := &telegraphv1.Waybill{
+		Id:                   d.ID,
+		EquipmentId:          d.EquipmentID,
+		WaybillDate:          protoTime(d.WaybillDate),
+		WaybillNumber:        d.WaybillNumber,
+		CreatedDate:          protoTime(d.CreatedDate),
+		BillingRoadMarkName:  d.BillingRoadMarkName,
+		WaybillSourceCode:    d.WaybillSourceCode,
+		LoadEmptyStatus:      d.LoadEmptyStatus,
+		OriginMarkName:       d.OriginMarkName,
+		DestinationMarkName:  d.DestinationMarkName,
+		SendingRoadMark:      d.SendingRoadMark,
+		BillOfLadingNumber:   d.BillOfLadingNumber,
+		BillOfLadingDate:     protoTime(d.BillOfLadingDate),
+		EquipmentWeight:      d.EquipmentWeight,
+		TareWeight:           d.TareWeight,
+		AllowableWeight:      d.AllowableWeight,
+		DunnageWeight:        d.DunnageWeight,
+		EquipmentWeightCode:  d.EquipmentWeightCode,
+		CommodityCode:        d.CommodityCode,
+		CommodityDescription: d.CommodityDescription,
+		OriginId:             d.OriginID,
+		DestinationId:        d.DestinationID,
+	}
+	for _, r := range d.Routes {
+		w.Routes = append(w.Routes, &telegraphv1.RoutePart{Scac: r.Scac, Junction: r.Junction, Splc: r.SPLC})
+	}
+	for _, p := range d.Parties {
+		w.Parties = append(w.Parties, &telegraphv1.Party{
+			PartyTypeCode:           p.PartyTypeCode,
+			PartyTypeSequenceNumber: int32(p.PartyTypeSequenceNumber),
+			CifNumber:               p.CifNumber,
+			CifName:                 p.CifName,
+		})
+	}
+	return w
+}
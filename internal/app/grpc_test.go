@@ -0,0 +1,200 @@
+package app_test
+
+import (
+	"context"
+	"github.com/coreyvan/backend-takehome/internal/app"
+	"github.com/coreyvan/backend-takehome/internal/config"
+	telegraphv1 "github.com/coreyvan/backend-takehome/proto/telegraph/v1"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"net"
+	"testing"
+	"time"
+)
+
+// newTestGRPC serves a small memory store over gRPC and returns a client
+// along with keys for an unrestricted and a TELGRAPH-only caller.
+func newTestGRPC(t *testing.T) (telegraphv1.TelegraphClient, map[string]string) {
+	t.Helper()
+
+	day := time.Date(2021, 8, 1, 0, 0, 0, 0, time.UTC)
+	mem := app.NewMemoryStore(
+		[]app.Location{{ID: "1", Station: "CHICAGO"}, {ID: "2", Station: "DENVER"}},
+		[]app.Equipment{
+			{ID: "1", Customer: "TELGRAPH", EquipmentID: "CAR1", DateAdded: day},
+			{ID: "2", Customer: "OTHERCO", EquipmentID: "CAR2", DateAdded: day},
+		},
+		[]app.Waybill{
+			{ID: "1", EquipmentID: "CAR1", WaybillDate: day, OriginID: "1", DestinationID: "2",
+				Routes: `[{"scac":"BNSF","junction":"CHGO"}]`, Parties: `[{"partyTypeCode":"CN","partyTypeSequenceNumber":1,"cifName":"ACME"}]`},
+			{ID: "2", EquipmentID: "CAR2", WaybillDate: day, OriginID: "2", DestinationID: "1"},
+		},
+		[]app.Event{
+			{ID: "1", EquipmentID: "CAR1", WaybillID: "1", LocationID: "1", SightingDate: day, PostingDate: day.Add(time.Hour)},
+			{ID: "2", EquipmentID: "CAR1", WaybillID: "1", LocationID: "2", SightingDate: day.Add(24 * time.Hour), PostingDate: day.Add(25 * time.Hour)},
+			{ID: "3", EquipmentID: "CAR2", WaybillID: "2", LocationID: "2", SightingDate: day, PostingDate: day.Add(2 * time.Hour)},
+		},
+	)
+
+	keys := make(map[string]string)
+	for name, customers := range map[string][]string{"admin": {app.AllCustomers}, "telgraph": {"TELGRAPH"}} {
+		key, _, err := mem.CreateAPIKey(name, customers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[name] = key
+	}
+
+	h := app.NewHTTP(zap.NewNop(), config.Default().HTTP, mem.Stores(), nil)
+	lis := bufconn.Listen(1 << 20)
+	go app.NewGRPC(h, "").Serve(lis)
+	t.Cleanup(func() { lis.Close() })
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return telegraphv1.NewTelegraphClient(conn), keys
+}
+
+func withKey(key string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "x-api-key", key)
+}
+
+// checkStatus checks err is a status with code and the problem code as its
+// ErrorInfo reason.
+func checkStatus(t *testing.T, err error, code codes.Code, reason string) {
+	t.Helper()
+
+	st := status.Convert(err)
+	if st.Code() != code {
+		t.Fatalf("code = %s (%v), want %s", st.Code(), err, code)
+	}
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok {
+			if info.Reason != reason {
+				t.Errorf("reason = %q, want %q", info.Reason, reason)
+			}
+			return
+		}
+	}
+	t.Errorf("%v has no ErrorInfo detail", err)
+}
+
+func TestGRPCAuthenticates(t *testing.T) {
+	client, _ := newTestGRPC(t)
+
+	_, err := client.ListLocations(context.Background(), &telegraphv1.ListLocationsRequest{})
+	checkStatus(t, err, codes.Unauthenticated, app.CodeAPIKeyRequired)
+
+	_, err = client.ListLocations(withKey("tg_unknown"), &telegraphv1.ListLocationsRequest{})
+	checkStatus(t, err, codes.Unauthenticated, app.CodeAPIKeyInvalid)
+}
+
+func TestGRPCWaybills(t *testing.T) {
+	client, keys := newTestGRPC(t)
+	ctx := withKey(keys["telgraph"])
+
+	waybill, err := client.GetWaybill(ctx, &telegraphv1.GetWaybillRequest{Id: "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(waybill.Routes) != 1 || waybill.Routes[0].Junction != "CHGO" || len(waybill.Parties) != 1 || waybill.Parties[0].CifName != "ACME" {
+		t.Errorf("routes and parties weren't decoded: %v", waybill)
+	}
+
+	// Another customer's waybill is hidden, as over HTTP.
+	_, err = client.GetWaybill(ctx, &telegraphv1.GetWaybillRequest{Id: "2"})
+	checkStatus(t, err, codes.NotFound, app.CodeWaybillNotFound)
+
+	_, err = client.GetWaybill(ctx, &telegraphv1.GetWaybillRequest{})
+	checkStatus(t, err, codes.InvalidArgument, app.CodeInvalidRequest)
+
+	list, err := client.ListWaybills(ctx, &telegraphv1.ListWaybillsRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Waybills) != 1 || list.Waybills[0].Id != "1" {
+		t.Errorf("waybills = %v, want only waybill 1", list.Waybills)
+	}
+
+	equipment, err := client.GetWaybillEquipment(ctx, &telegraphv1.GetWaybillRequest{Id: "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(equipment.Equipment) != 1 || equipment.Equipment[0].EquipmentId != "CAR1" || equipment.Equipment[0].DateRemoved != nil {
+		t.Errorf("equipment = %v, want the active CAR1 record", equipment.Equipment)
+	}
+
+	locations, err := client.GetWaybillLocations(ctx, &telegraphv1.GetWaybillRequest{Id: "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(locations.Locations) != 2 {
+		t.Errorf("locations = %v, want the origin and destination", locations.Locations)
+	}
+}
+
+func TestGRPCStreamEvents(t *testing.T) {
+	client, keys := newTestGRPC(t)
+	ctx, cancel := context.WithTimeout(withKey(keys["admin"]), 5*time.Second)
+	defer cancel()
+
+	after := timestamppb.New(time.Date(2021, 8, 1, 0, 0, 0, 0, time.UTC))
+	stream, err := client.StreamEvents(ctx, &telegraphv1.StreamEventsRequest{After: after})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cursor string
+	for _, want := range []string{"1", "3", "2"} {
+		res, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Event.Id != want {
+			t.Fatalf("got event %s, want %s", res.Event.Id, want)
+		}
+		cursor = res.Cursor
+	}
+
+	// Resuming after the first event skips it; the waybill filter skips the
+	// other car's event.
+	stream, err = client.StreamEvents(ctx, &telegraphv1.StreamEventsRequest{WaybillId: "1", After: after})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream, err = client.StreamEvents(ctx, &telegraphv1.StreamEventsRequest{WaybillId: "1", Cursor: first.Cursor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Event.Id != "2" || res.Cursor != cursor {
+		t.Errorf("resumed at event %s with cursor %s, want event 2 with cursor %s", res.Event.Id, res.Cursor, cursor)
+	}
+
+	stream, err = client.StreamEvents(ctx, &telegraphv1.StreamEventsRequest{Cursor: "yesterday"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = stream.Recv()
+	checkStatus(t, err, codes.InvalidArgument, app.CodeInvalidRequest)
+}
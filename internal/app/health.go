@@ -0,0 +1,125 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"github.com/coreyvan/backend-takehome/internal/database"
+	"github.com/coreyvan/backend-takehome/internal/migrate"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+// readyTimeout bounds each of /readyz's database checks, so a hung database
+// fails the probe rather than holding it.
+const readyTimeout = 2 * time.Second
+
+// Health is /healthz's report.
+type Health struct {
+	Status string `json:"status"`
+}
+
+// Readiness is /readyz's report. It's ready when every check passed.
+type Readiness struct {
+	Ready  bool          `json:"ready"`
+	Checks []HealthCheck `json:"checks"`
+}
+
+// HealthCheck is the outcome of one readiness check. Detail says why it
+// failed.
+type HealthCheck struct {
+	Name   string `json:"name"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Healthz reports the process is up, without checking what it depends on, for
+// liveness probes.
+func (h *HTTP) Healthz() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, Health{Status: "ok"})
+	}
+}
+
+// Readyz reports whether the server should receive traffic: it isn't shutting
+// down, the database answers with a current schema, and the data was ingested
+// recently enough. It responds 503 when any check fails.
+func (h *HTTP) Readyz() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		checks := []HealthCheck{h.checkServing()}
+		if h.db != nil {
+			checks = append(checks, h.checkDatabase(ctx), h.checkMigrations(ctx))
+		}
+		checks = append(checks, h.checkIngest(ctx))
+
+		res := Readiness{Ready: true, Checks: checks}
+		for _, check := range checks {
+			res.Ready = res.Ready && check.OK
+		}
+		status := http.StatusOK
+		if !res.Ready {
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, res)
+	}
+}
+
+func (h *HTTP) checkServing() HealthCheck {
+	select {
+	case <-h.draining:
+		return HealthCheck{Name: "serving", Detail: "the server is shutting down"}
+	default:
+		return HealthCheck{Name: "serving", OK: true}
+	}
+}
+
+func (h *HTTP) checkDatabase(ctx context.Context) HealthCheck {
+	ctx, cancel := context.WithTimeout(ctx, readyTimeout)
+	defer cancel()
+
+	if err := database.Ping(ctx, h.db); err != nil {
+		// The error can name the host, and /readyz needs no API key.
+		h.log.Sugar().Errorf("readiness: %v", err)
+		return HealthCheck{Name: "database", Detail: "the database can't be reached"}
+	}
+	return HealthCheck{Name: "database", OK: true}
+}
+
+func (h *HTTP) checkMigrations(ctx context.Context) HealthCheck {
+	ctx, cancel := context.WithTimeout(ctx, readyTimeout)
+	defer cancel()
+
+	m, err := migrate.New(h.db, h.log)
+	if err == nil {
+		err = m.RequireCurrent(ctx)
+	}
+	if err != nil {
+		h.log.Sugar().Errorf("readiness: %v", err)
+		return HealthCheck{Name: "migrations", Detail: "the schema isn't current"}
+	}
+	return HealthCheck{Name: "migrations", OK: true}
+}
+
+// checkIngest fails when MaxIngestAge is set and the latest ingest run of any
+// dataset is older, or there is none.
+func (h *HTTP) checkIngest(ctx context.Context) HealthCheck {
+	ctx, cancel := context.WithTimeout(ctx, readyTimeout)
+	defer cancel()
+
+	rev, err := h.revisions.Revision(ctx, DatasetLocations, DatasetEquipment, DatasetWaybills, DatasetEvents)
+	if err != nil {
+		h.log.Sugar().Errorf("readiness: %v", err)
+		return HealthCheck{Name: "ingest", Detail: "ingest runs can't be read"}
+	}
+	if h.cfg.MaxIngestAge == 0 {
+		return HealthCheck{Name: "ingest", OK: true}
+	}
+	if rev.ID == 0 {
+		return HealthCheck{Name: "ingest", Detail: "no ingest run is recorded"}
+	}
+	if age := time.Since(rev.ModifiedAt); age > h.cfg.MaxIngestAge {
+		return HealthCheck{Name: "ingest", Detail: fmt.Sprintf("the last ingest run was %s ago, more than %s", age.Round(time.Second), h.cfg.MaxIngestAge)}
+	}
+	return HealthCheck{Name: "ingest", OK: true}
+}
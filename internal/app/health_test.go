@@ -0,0 +1,113 @@
+package app_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/coreyvan/backend-takehome/internal/app"
+	"github.com/coreyvan/backend-takehome/internal/config"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readyz fetches /readyz and returns its status and the failed checks.
+func readyz(t *testing.T, url string) (int, map[string]string) {
+	t.Helper()
+
+	res := get(t, url+"/readyz", "", "")
+	var readiness app.Readiness
+	if err := json.NewDecoder(res.Body).Decode(&readiness); err != nil {
+		t.Fatal(err)
+	}
+	failed := make(map[string]string)
+	for _, check := range readiness.Checks {
+		if !check.OK {
+			failed[check.Name] = check.Detail
+		}
+	}
+	return res.StatusCode, failed
+}
+
+func TestReadyzMaxIngestAge(t *testing.T) {
+	db := newTestDB(t)
+	for _, tc := range []struct {
+		age   time.Duration
+		ready bool
+	}{
+		{0, true},
+		{time.Hour, true},
+		{time.Nanosecond, false},
+	} {
+		cfg := config.Default().HTTP
+		cfg.MaxIngestAge = tc.age
+		srv := httptest.NewServer(app.NewHTTP(zap.NewNop(), cfg, app.NewGormStore(db).Stores(), db).Handler())
+
+		status, failed := readyz(t, srv.URL)
+		if ready := status == http.StatusOK; ready != tc.ready {
+			t.Errorf("max ingest age %s: status = %d, failed = %v", tc.age, status, failed)
+		}
+		if _, ok := failed["ingest"]; ok == tc.ready {
+			t.Errorf("max ingest age %s: failed = %v", tc.age, failed)
+		}
+		srv.Close()
+	}
+}
+
+// TestReadyzMigrations checks a stale schema fails /readyz without saying
+// more than that to unauthenticated callers.
+func TestReadyzMigrations(t *testing.T) {
+	db := newTestDB(t)
+	if err := db.Exec("DELETE FROM schema_migrations WHERE version = (SELECT MAX(version) FROM schema_migrations)").Error; err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(app.NewHTTP(zap.NewNop(), config.Default().HTTP, app.NewGormStore(db).Stores(), db).Handler())
+	t.Cleanup(srv.Close)
+
+	status, failed := readyz(t, srv.URL)
+	if status != http.StatusServiceUnavailable || failed["migrations"] != "the schema isn't current" {
+		t.Errorf("readyz with a stale schema = %d, failed = %v", status, failed)
+	}
+}
+
+// TestShutdown checks shutting down fails /readyz, leaves /healthz alone and
+// ends event streams with an error event clients can reconnect after.
+func TestShutdown(t *testing.T) {
+	db := newTestDB(t)
+	key, _, err := app.CreateAPIKey(db, "admin", []string{app.AllCustomers})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := app.NewHTTP(zap.NewNop(), config.Default().HTTP, app.NewGormStore(db).Stores(), db)
+	srv := httptest.NewServer(h.Handler())
+	t.Cleanup(srv.Close)
+
+	stream := get(t, srv.URL+"/v2/events/stream", key, "")
+	if stream.StatusCode != http.StatusOK {
+		t.Fatalf("stream = %d", stream.StatusCode)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := h.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := io.ReadAll(stream.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(body), "event:error") || !strings.Contains(string(body), app.CodeShuttingDown) {
+		t.Errorf("stream ended with %q, want a shutting_down error event", body)
+	}
+
+	if status, failed := readyz(t, srv.URL); status != http.StatusServiceUnavailable || failed["serving"] == "" {
+		t.Errorf("readyz after shutdown = %d, failed = %v", status, failed)
+	}
+	if res := get(t, srv.URL+"/healthz", "", ""); res.StatusCode != http.StatusOK {
+		t.Errorf("healthz after shutdown = %d", res.StatusCode)
+	}
+}
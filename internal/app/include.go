@@ -0,0 +1,191 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"strings"
+)
+
+// Resources a waybill response can embed with ?include=.
+const (
+	IncludeEquipment = "equipment"
+	IncludeEvents    = "events"
+	IncludeLocations = "locations"
+	IncludeRoute     = "route"
+	IncludeParties   = "parties"
+)
+
+var waybillIncludes = []string{IncludeEquipment, IncludeEvents, IncludeLocations, IncludeRoute, IncludeParties}
+
+// WaybillDetail is how the API represents a waybill: with its route and
+// parties decoded, and the related resources asked for with ?include= under
+// Included.
+type WaybillDetail struct {
+	Waybill
+	Routes   []RoutePart      `json:"routes"`
+	Parties  []Party          `json:"parties"`
+	Included *WaybillIncludes `json:"included,omitempty"`
+
+	// legacy keeps the route and parties as the JSON strings API version 1
+	// served.
+	legacy bool
+}
+
+// legacyWaybillDetail is a WaybillDetail as API version 1 serves it.
+type legacyWaybillDetail struct {
+	Waybill
+	Included *WaybillIncludes `json:"included,omitempty"`
+}
+
+func (d WaybillDetail) MarshalJSON() ([]byte, error) {
+	if d.legacy {
+		return json.Marshal(legacyWaybillDetail{Waybill: d.Waybill, Included: d.Included})
+	}
+
+	// A type without the method marshals the fields as usual.
+	type detail WaybillDetail
+	return json.Marshal(detail(d))
+}
+
+// WaybillIncludes holds a waybill's related resources, each the same as its
+// /waybills/:id/<resource> endpoint returns. Resources that weren't asked for
+// are left out.
+type WaybillIncludes struct {
+	Equipment *[]Equipment `json:"equipment,omitempty"`
+	Events    *[]Event     `json:"events,omitempty"`
+	Locations *[]Location  `json:"locations,omitempty"`
+	Route     *[]RoutePart `json:"route,omitempty"`
+	Parties   *[]Party     `json:"parties,omitempty"`
+}
+
+// queryIncludes reads the include query param, a comma-separated list that may
+// also be repeated, recording a FieldError for unknown resources.
+func queryIncludes(c *gin.Context, errs *[]FieldError) map[string]bool {
+	include := make(map[string]bool)
+	for _, v := range c.QueryArray("include") {
+		for _, name := range strings.Split(v, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			if !contains(waybillIncludes, name) {
+				*errs = append(*errs, FieldError{Name: "include", In: "query", Reason: fmt.Sprintf("%s isn't one of %s", name, strings.Join(waybillIncludes, ", "))})
+				continue
+			}
+			include[name] = true
+		}
+	}
+	return include
+}
+
+// waybillDetails represents waybills as the API version asks, embedding the
+// included resources. Each resource is read with one query across all the
+// waybills rather than one per waybill.
+func (h *HTTP) waybillDetails(ctx context.Context, scope Scope, waybills []Waybill, include map[string]bool, version int) ([]WaybillDetail, error) {
+	details := make([]WaybillDetail, len(waybills))
+	for k, w := range waybills {
+		route, err := w.DecodeRoutes()
+		if err != nil {
+			return nil, err
+		}
+		parties, err := w.DecodeParties()
+		if err != nil {
+			return nil, err
+		}
+		details[k] = WaybillDetail{Waybill: w, Routes: route, Parties: parties, legacy: version == APIVersion1}
+	}
+	if len(include) == 0 || len(waybills) == 0 {
+		return details, nil
+	}
+
+	var (
+		waybillIDs   []string
+		equipmentIDs []string
+		locationIDs  []string
+	)
+	for _, w := range waybills {
+		waybillIDs = append(waybillIDs, w.ID)
+		equipmentIDs = append(equipmentIDs, w.EquipmentID)
+		locationIDs = append(locationIDs, w.OriginID, w.DestinationID)
+	}
+
+	// The equipment in effect is bounded by the waybill's last sighting, so
+	// events are read for either include.
+	eventsByWaybill := make(map[string][]Event)
+	if include[IncludeEvents] || include[IncludeEquipment] {
+		events, err := h.events.ListEvents(ctx, Scope{}, EventFilter{WaybillIDs: waybillIDs})
+		if err != nil {
+			return nil, fmt.Errorf("finding waybill events: %w", err)
+		}
+		for _, e := range events {
+			eventsByWaybill[e.WaybillID] = append(eventsByWaybill[e.WaybillID], e)
+		}
+	}
+
+	recordsByCar := make(map[string][]Equipment)
+	if include[IncludeEquipment] {
+		// A car may have belonged to another customer before or after this
+		// shipment; the scope keeps those records hidden.
+		records, err := h.equipment.ListEquipment(ctx, scope, EquipmentFilter{EquipmentIDs: equipmentIDs})
+		if err != nil {
+			return nil, fmt.Errorf("finding equipment records: %w", err)
+		}
+		for _, r := range records {
+			recordsByCar[r.EquipmentID] = append(recordsByCar[r.EquipmentID], r)
+		}
+	}
+
+	var locations []Location
+	if include[IncludeLocations] {
+		var err error
+		if locations, err = h.locations.LocationsByID(ctx, locationIDs); err != nil {
+			return nil, fmt.Errorf("finding waybill locations: %w", err)
+		}
+	}
+
+	for k := range details {
+		d := &details[k]
+		d.Included = &WaybillIncludes{}
+		events := eventsByWaybill[d.ID]
+
+		if include[IncludeEquipment] {
+			until := d.WaybillDate
+			if len(events) > 0 && events[len(events)-1].SightingDate.After(until) {
+				until = events[len(events)-1].SightingDate
+			}
+			equipment := []Equipment{}
+			if e, ok := EquipmentAt(recordsByCar[d.EquipmentID], d.WaybillDate, until); ok {
+				equipment = append(equipment, e)
+			}
+			d.Included.Equipment = &equipment
+		}
+
+		if include[IncludeEvents] {
+			if events == nil {
+				events = []Event{}
+			}
+			d.Included.Events = &events
+		}
+
+		if include[IncludeLocations] {
+			found := []Location{}
+			for _, l := range locations {
+				if l.ID == d.OriginID || l.ID == d.DestinationID {
+					found = append(found, l)
+				}
+			}
+			d.Included.Locations = &found
+		}
+
+		if include[IncludeRoute] {
+			d.Included.Route = &d.Routes
+		}
+		if include[IncludeParties] {
+			d.Included.Parties = &d.Parties
+		}
+	}
+
+	return details, nil
+}
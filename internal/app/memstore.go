@@ -0,0 +1,344 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStore implements the stores over fixed slices, such as the CSV
+// fixtures, so the API can run without a database. Only API keys can be
+// added after it's built.
+type MemoryStore struct {
+	locations []Location
+	equipment []Equipment
+	waybills  []Waybill
+	events    []Event
+
+	// loadedAt is the revision of the data, which never changes.
+	loadedAt time.Time
+
+	mu   sync.Mutex
+	keys []APIKey
+}
+
+func NewMemoryStore(locations []Location, equipment []Equipment, waybills []Waybill, events []Event) *MemoryStore {
+	s := &MemoryStore{loadedAt: time.Now().UTC().Truncate(time.Second)}
+
+	// Locations and waybills are ordered by ID, as the database returns
+	// them.
+	s.locations = append([]Location(nil), locations...)
+	sort.SliceStable(s.locations, func(i, j int) bool { return s.locations[i].ID < s.locations[j].ID })
+	s.waybills = append([]Waybill(nil), waybills...)
+	sort.SliceStable(s.waybills, func(i, j int) bool { return s.waybills[i].ID < s.waybills[j].ID })
+
+	s.equipment = append([]Equipment(nil), equipment...)
+	sort.SliceStable(s.equipment, func(i, j int) bool {
+		a, b := s.equipment[i], s.equipment[j]
+		if !a.DateAdded.Equal(b.DateAdded) {
+			return a.DateAdded.Before(b.DateAdded)
+		}
+		return a.ID < b.ID
+	})
+
+	s.events = append([]Event(nil), events...)
+	sort.SliceStable(s.events, func(i, j int) bool {
+		a, b := s.events[i], s.events[j]
+		if !a.SightingDate.Equal(b.SightingDate) {
+			return a.SightingDate.Before(b.SightingDate)
+		}
+		return a.ID < b.ID
+	})
+
+	return s
+}
+
+// Stores returns a Stores with every store backed by s.
+func (s *MemoryStore) Stores() Stores {
+	return Stores{Waybills: s, Events: s, Equipment: s, Locations: s, Keys: s, Revisions: s}
+}
+
+func (s *MemoryStore) ListEquipment(_ context.Context, scope Scope, filter EquipmentFilter) ([]Equipment, error) {
+	equipment := []Equipment{}
+	for _, e := range s.equipment {
+		if !scope.Allows(e.Customer) {
+			continue
+		}
+		if len(filter.EquipmentIDs) > 0 && !contains(filter.EquipmentIDs, e.EquipmentID) {
+			continue
+		}
+		if !filter.AsOf.IsZero() && !e.ActiveAt(filter.AsOf) {
+			continue
+		}
+		equipment = append(equipment, e)
+	}
+	return equipment, nil
+}
+
+func (s *MemoryStore) EachEquipment(ctx context.Context, scope Scope, filter EquipmentFilter, fn func(Equipment) error) error {
+	equipment, err := s.ListEquipment(ctx, scope, filter)
+	if err != nil {
+		return err
+	}
+	for _, e := range equipment {
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) ListEvents(_ context.Context, scope Scope, filter EventFilter) ([]Event, error) {
+	records := s.records(scope)
+
+	events := []Event{}
+	for _, e := range s.events {
+		if s.matches(e, records, scope, filter) {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+func (s *MemoryStore) EachEvent(ctx context.Context, scope Scope, filter EventFilter, fn func(Event) error) error {
+	events, err := s.ListEvents(ctx, scope, filter)
+	if err != nil {
+		return err
+	}
+	for _, e := range events {
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) EventsSince(_ context.Context, scope Scope, filter EventFilter, cursor EventCursor, limit int) ([]Event, error) {
+	records := s.records(scope)
+
+	events := []Event{}
+	for _, e := range s.events {
+		after := e.PostingDate.After(cursor.PostingDate) || (cursor.ID != "" && e.PostingDate.Equal(cursor.PostingDate) && e.ID > cursor.ID)
+		if after && s.matches(e, records, scope, filter) {
+			events = append(events, e)
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		a, b := events[i], events[j]
+		if !a.PostingDate.Equal(b.PostingDate) {
+			return a.PostingDate.Before(b.PostingDate)
+		}
+		return a.ID < b.ID
+	})
+	if limit > 0 && len(events) > limit {
+		events = events[:limit]
+	}
+	return events, nil
+}
+
+func (s *MemoryStore) LatestEventCursor(context.Context) (EventCursor, error) {
+	var latest EventCursor
+	for _, e := range s.events {
+		if e.PostingDate.After(latest.PostingDate) || (e.PostingDate.Equal(latest.PostingDate) && e.ID > latest.ID) {
+			latest = EventCursor{PostingDate: e.PostingDate, ID: e.ID}
+		}
+	}
+	return latest, nil
+}
+
+func (s *MemoryStore) matches(e Event, records map[string][]Equipment, scope Scope, filter EventFilter) bool {
+	switch {
+	case records != nil && !sightedFor(records[e.EquipmentID], scope, e.SightingDate):
+		return false
+	case filter.WaybillID != "" && e.WaybillID != filter.WaybillID:
+		return false
+	case len(filter.WaybillIDs) > 0 && !contains(filter.WaybillIDs, e.WaybillID):
+		return false
+	case filter.EquipmentID != "" && e.EquipmentID != filter.EquipmentID:
+		return false
+	case len(filter.Codes) > 0 && !contains(filter.Codes, e.SightingEventCode):
+		return false
+	case !filter.PostedAfter.IsZero() && !e.PostingDate.After(filter.PostedAfter):
+		return false
+	}
+	return true
+}
+
+func (s *MemoryStore) ListLocations(context.Context) ([]Location, error) {
+	return append([]Location{}, s.locations...), nil
+}
+
+func (s *MemoryStore) EachLocation(_ context.Context, fn func(Location) error) error {
+	for _, l := range s.locations {
+		if err := fn(l); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) LocationsByID(_ context.Context, ids []string) ([]Location, error) {
+	locations := []Location{}
+	for _, l := range s.locations {
+		if contains(ids, l.ID) {
+			locations = append(locations, l)
+		}
+	}
+	return locations, nil
+}
+
+func (s *MemoryStore) ListWaybills(_ context.Context, scope Scope, filter WaybillFilter) ([]Waybill, error) {
+	records := s.records(scope)
+
+	waybills := []Waybill{}
+	for _, w := range s.waybills {
+		if records != nil && !s.shippedFor(w, records[w.EquipmentID], scope) {
+			continue
+		}
+		if (filter.WaybillNumber != "" && w.WaybillNumber != filter.WaybillNumber) ||
+			(filter.BillOfLadingNumber != "" && w.BillOfLadingNumber != filter.BillOfLadingNumber) ||
+			(filter.EquipmentID != "" && w.EquipmentID != filter.EquipmentID) ||
+			(len(filter.IDs) > 0 && !contains(filter.IDs, w.ID)) ||
+			(len(filter.EquipmentIDs) > 0 && !contains(filter.EquipmentIDs, w.EquipmentID)) {
+			continue
+		}
+		waybills = append(waybills, w)
+	}
+	return waybills, nil
+}
+
+func (s *MemoryStore) EachWaybill(ctx context.Context, scope Scope, filter WaybillFilter, fn func(Waybill) error) error {
+	waybills, err := s.ListWaybills(ctx, scope, filter)
+	if err != nil {
+		return err
+	}
+	for _, w := range waybills {
+		if err := fn(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) WaybillByID(_ context.Context, scope Scope, id string) (Waybill, error) {
+	records := s.records(scope)
+
+	for _, w := range s.waybills {
+		if w.ID == id && (records == nil || s.shippedFor(w, records[w.EquipmentID], scope)) {
+			return w, nil
+		}
+	}
+	return Waybill{}, ErrNotFound
+}
+
+// records returns every customer's records of the cars that have belonged to
+// the scope's customers, by car and ordered by date added, or nil when the
+// scope is unrestricted.
+func (s *MemoryStore) records(scope Scope) map[string][]Equipment {
+	if !scope.Restricted {
+		return nil
+	}
+
+	cars := make(map[string]bool)
+	for _, e := range s.equipment {
+		if scope.Allows(e.Customer) {
+			cars[e.EquipmentID] = true
+		}
+	}
+	records := make(map[string][]Equipment)
+	for _, e := range s.equipment {
+		if cars[e.EquipmentID] {
+			records[e.EquipmentID] = append(records[e.EquipmentID], e)
+		}
+	}
+	return records
+}
+
+// shippedFor reports whether a waybill is one of the scope's customers'
+// shipments, by the car record EquipmentAt picks for it.
+func (s *MemoryStore) shippedFor(w Waybill, records []Equipment, scope Scope) bool {
+	if len(records) == 0 {
+		return false
+	}
+	until := w.WaybillDate
+	for _, e := range s.events {
+		if e.WaybillID == w.ID && e.SightingDate.After(until) {
+			until = e.SightingDate
+		}
+	}
+	e, ok := EquipmentAt(records, w.WaybillDate, until)
+	return ok && scope.Allows(e.Customer)
+}
+
+// sightedFor reports whether the record covering a car at a sighting belongs
+// to one of the scope's customers.
+func sightedFor(records []Equipment, scope Scope, at time.Time) bool {
+	for _, e := range records {
+		if e.ActiveAt(at) {
+			return scope.Allows(e.Customer)
+		}
+	}
+	return false
+}
+
+// CreateAPIKey adds a key bound to customers and returns the plaintext key.
+func (s *MemoryStore) CreateAPIKey(name string, customers []string) (string, APIKey, error) {
+	key, k, err := newAPIKey(name, customers)
+	if err != nil {
+		return "", APIKey{}, err
+	}
+	return key, s.addAPIKey(k), nil
+}
+
+// AddAPIKey adds a plaintext key chosen elsewhere, such as in the config,
+// bound to customers.
+func (s *MemoryStore) AddAPIKey(name, key string, customers []string) (APIKey, error) {
+	k, err := apiKeyRecord(name, key, customers)
+	if err != nil {
+		return APIKey{}, err
+	}
+	return s.addAPIKey(k), nil
+}
+
+func (s *MemoryStore) addAPIKey(k APIKey) APIKey {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k.ID = uint(len(s.keys) + 1)
+	k.CreatedAt = time.Now().UTC()
+	s.keys = append(s.keys, k)
+	return k
+}
+
+func (s *MemoryStore) ActiveAPIKey(_ context.Context, hash string) (APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, k := range s.keys {
+		if k.Hash == hash && k.RevokedAt == nil {
+			return k, nil
+		}
+	}
+	return APIKey{}, ErrNotFound
+}
+
+func (s *MemoryStore) TouchAPIKey(_ context.Context, id uint, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for k := range s.keys {
+		if s.keys[k].ID == id {
+			s.keys[k].LastUsedAt = &at
+			return nil
+		}
+	}
+	return fmt.Errorf("no api key with id %d", id)
+}
+
+// Revision is the same for every dataset: the time the store was created.
+func (s *MemoryStore) Revision(context.Context, ...string) (Revision, error) {
+	return Revision{ID: 1, ModifiedAt: s.loadedAt}, nil
+}
@@ -0,0 +1,139 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"github.com/coreyvan/backend-takehome/internal/app"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func august(day int) time.Time {
+	return time.Date(2021, 8, day, 0, 0, 0, 0, time.UTC)
+}
+
+// handedOver is a car that TELGRAPH hands to OTHERCO on August 15th, with a
+// shipment and a sighting under each.
+func handedOver() *app.MemoryStore {
+	return app.NewMemoryStore(
+		[]app.Location{{ID: "2"}, {ID: "1"}},
+		[]app.Equipment{
+			{ID: "b", EquipmentID: "TILX200001", Customer: "OTHERCO", DateAdded: august(15)},
+			{ID: "a", EquipmentID: "TILX200001", Customer: "TELGRAPH", DateAdded: august(1), DateRemoved: august(15)},
+		},
+		[]app.Waybill{
+			{ID: "2", EquipmentID: "TILX200001", WaybillDate: august(20)},
+			{ID: "1", EquipmentID: "TILX200001", WaybillDate: august(5)},
+		},
+		[]app.Event{
+			{ID: "11", EquipmentID: "TILX200001", WaybillID: "1", SightingDate: august(6), PostingDate: august(21)},
+			{ID: "21", EquipmentID: "TILX200001", WaybillID: "2", SightingDate: august(21), PostingDate: august(21)},
+			{ID: "22", EquipmentID: "TILX200001", WaybillID: "2", SightingDate: august(22), PostingDate: august(22)},
+		},
+	)
+}
+
+func ids[T any](rows []T, id func(T) string) []string {
+	out := []string{}
+	for _, r := range rows {
+		out = append(out, id(r))
+	}
+	return out
+}
+
+// TestMemoryStoreScopesByRecordInEffect checks each customer sees the
+// shipments and sightings of the car from while it was theirs, ordered as the
+// database orders them.
+func TestMemoryStoreScopesByRecordInEffect(t *testing.T) {
+	ctx := context.Background()
+	s := handedOver()
+	waybillID := func(w app.Waybill) string { return w.ID }
+	eventID := func(e app.Event) string { return e.ID }
+
+	locations, err := s.ListLocations(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(locations, func(l app.Location) string { return l.ID }); !reflect.DeepEqual(got, []string{"1", "2"}) {
+		t.Errorf("locations %v, want them by ID", got)
+	}
+
+	for _, tc := range []struct {
+		scope    app.Scope
+		waybills []string
+		events   []string
+	}{
+		{app.Scope{}, []string{"1", "2"}, []string{"11", "21", "22"}},
+		{app.Scope{Customers: []string{"TELGRAPH"}, Restricted: true}, []string{"1"}, []string{"11"}},
+		{app.Scope{Customers: []string{"OTHERCO"}, Restricted: true}, []string{"2"}, []string{"21", "22"}},
+	} {
+		waybills, err := s.ListWaybills(ctx, tc.scope, app.WaybillFilter{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := ids(waybills, waybillID); !reflect.DeepEqual(got, tc.waybills) {
+			t.Errorf("%v: waybills %v, want %v", tc.scope.Customers, got, tc.waybills)
+		}
+		events, err := s.ListEvents(ctx, tc.scope, app.EventFilter{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := ids(events, eventID); !reflect.DeepEqual(got, tc.events) {
+			t.Errorf("%v: events %v, want %v", tc.scope.Customers, got, tc.events)
+		}
+	}
+
+	other := app.Scope{Customers: []string{"OTHERCO"}, Restricted: true}
+	if _, err := s.WaybillByID(ctx, other, "1"); !errors.Is(err, app.ErrNotFound) {
+		t.Errorf("OTHERCO reading TELGRAPH's waybill: err = %v, want ErrNotFound", err)
+	}
+}
+
+// TestMemoryStoreEventsSince checks a cursor with an ID resumes after that
+// event, one without is past every event posted at its date, and streams
+// start from the latest event.
+func TestMemoryStoreEventsSince(t *testing.T) {
+	ctx := context.Background()
+	s := handedOver()
+	eventID := func(e app.Event) string { return e.ID }
+
+	latest, err := s.LatestEventCursor(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (app.EventCursor{PostingDate: august(22), ID: "22"}); latest != want {
+		t.Errorf("latest cursor %+v, want %+v", latest, want)
+	}
+
+	for _, tc := range []struct {
+		cursor app.EventCursor
+		want   []string
+	}{
+		{app.EventCursor{}, []string{"11", "21", "22"}},
+		{app.EventCursor{PostingDate: august(21), ID: "11"}, []string{"21", "22"}},
+		{app.EventCursor{PostingDate: august(21)}, []string{"22"}},
+		{latest, []string{}},
+	} {
+		events, err := s.EventsSince(ctx, app.Scope{}, app.EventFilter{}, tc.cursor, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := ids(events, eventID); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("events since %+v: %v, want %v", tc.cursor, got, tc.want)
+		}
+	}
+}
+
+func TestMemoryStoreAddAPIKey(t *testing.T) {
+	s := handedOver()
+	if _, err := s.AddAPIKey("local", "tg_short", []string{app.AllCustomers}); err == nil {
+		t.Error("a key under 16 characters after tg_ was accepted")
+	}
+	if _, err := s.AddAPIKey("local", "0123456789abcdef0123", []string{app.AllCustomers}); err == nil {
+		t.Error("a key without the tg_ prefix was accepted")
+	}
+	if _, err := s.AddAPIKey("local", "tg_0123456789abcdef", []string{app.AllCustomers}); err != nil {
+		t.Errorf("adding a valid key: %v", err)
+	}
+}
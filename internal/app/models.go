@@ -1,6 +1,8 @@
 package app
 
 import (
+	"encoding/json"
+	"fmt"
 	"time"
 )
 
@@ -76,6 +78,7 @@ type Event struct {
 type RoutePart struct {
 	Scac     string `json:"scac"`
 	Junction string `json:"junction,omitempty"`
+	SPLC     string `json:"splc,omitempty"`
 }
 
 type Party struct {
@@ -84,3 +87,81 @@ type Party struct {
 	CifNumber               string `json:"cifNumber,omitempty"`
 	CifName                 string `json:"cifName"`
 }
+
+// DecodeRoutes decodes the waybill's route, which is stored as JSON. A
+// waybill without one has no route parts.
+func (w Waybill) DecodeRoutes() ([]RoutePart, error) {
+	route := []RoutePart{}
+	if w.Routes == "" {
+		return route, nil
+	}
+	if err := json.Unmarshal([]byte(w.Routes), &route); err != nil {
+		return nil, fmt.Errorf("unmarshaling routes of waybill %s: %w", w.ID, err)
+	}
+	if route == nil {
+		route = []RoutePart{}
+	}
+	return route, nil
+}
+
+// DecodeParties decodes the waybill's parties, which are stored as JSON.
+func (w Waybill) DecodeParties() ([]Party, error) {
+	parties := []Party{}
+	if w.Parties == "" {
+		return parties, nil
+	}
+	if err := json.Unmarshal([]byte(w.Parties), &parties); err != nil {
+		return nil, fmt.Errorf("unmarshaling parties of waybill %s: %w", w.ID, err)
+	}
+	if parties == nil {
+		parties = []Party{}
+	}
+	return parties, nil
+}
+
+// ActiveAt reports whether the equipment record's fleet membership window
+// covers t. Windows include DateAdded but not DateRemoved, so a car moving
+// between records belongs to one at any time. A zero DateRemoved means the
+// record is still active.
+func (e Equipment) ActiveAt(t time.Time) bool {
+	if t.Before(e.DateAdded) {
+		return false
+	}
+	return e.DateRemoved.IsZero() || t.Before(e.DateRemoved)
+}
+
+// ActiveDuring reports whether the equipment record's fleet membership window
+// overlaps the period between start and end, inclusive. The window's bounds
+// are those of ActiveAt, so ActiveDuring(t, t) is ActiveAt(t).
+func (e Equipment) ActiveDuring(start, end time.Time) bool {
+	if end.Before(e.DateAdded) {
+		return false
+	}
+	return e.DateRemoved.IsZero() || start.Before(e.DateRemoved)
+}
+
+// EquipmentAt picks the equipment record in effect for a shipment. The record
+// whose window covers at wins; failing that, the earliest record whose window
+// overlaps the shipment's sightings between at and until is used, since
+// waybill dates are often truncated to midnight before the car joined the fleet.
+func EquipmentAt(records []Equipment, at, until time.Time) (Equipment, bool) {
+	for _, e := range records {
+		if e.ActiveAt(at) {
+			return e, true
+		}
+	}
+
+	var (
+		found Equipment
+		ok    bool
+	)
+	for _, e := range records {
+		if !e.ActiveDuring(at, until) {
+			continue
+		}
+		if !ok || e.DateAdded.Before(found.DateAdded) {
+			found, ok = e, true
+		}
+	}
+	return found, ok
+}
@@ -0,0 +1,39 @@
+package app_test
+
+import (
+	"github.com/coreyvan/backend-takehome/internal/app"
+	"testing"
+	"time"
+)
+
+func TestEquipmentWindowBounds(t *testing.T) {
+	added := time.Date(2021, 8, 1, 0, 0, 0, 0, time.UTC)
+	removed := time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC)
+	e := app.Equipment{DateAdded: added, DateRemoved: removed}
+
+	for _, tc := range []struct {
+		at     time.Time
+		active bool
+	}{
+		{added.Add(-time.Second), false},
+		{added, true},
+		{removed.Add(-time.Second), true},
+		{removed, false},
+	} {
+		if got := e.ActiveAt(tc.at); got != tc.active {
+			t.Errorf("ActiveAt(%s) = %v, want %v", tc.at, got, tc.active)
+		}
+		if got := e.ActiveDuring(tc.at, tc.at); got != tc.active {
+			t.Errorf("ActiveDuring(%s, %s) = %v, want %v", tc.at, tc.at, got, tc.active)
+		}
+	}
+	if !e.ActiveDuring(added.Add(-time.Hour), removed) {
+		t.Error("a period ending at DateRemoved doesn't overlap the window")
+	}
+
+	// A car moved to another record on DateRemoved belongs to the new one.
+	next := app.Equipment{ID: "next", DateAdded: removed}
+	if got, ok := app.EquipmentAt([]app.Equipment{e, next}, removed, removed); !ok || got.ID != "next" {
+		t.Errorf("EquipmentAt(DateRemoved) = %+v, %v, want the next record", got, ok)
+	}
+}
@@ -0,0 +1,756 @@
+package app
+
+import (
+	_ "embed"
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	openAPIVersion = "3.0.3"
+	apiVersion     = "1.0.0"
+)
+
+//go:embed docs/index.html
+var docsPage []byte
+
+// The OpenAPI document, limited to the parts this API uses.
+type (
+	openAPIDoc struct {
+		OpenAPI    string                          `json:"openapi"`
+		Info       openAPIInfo                     `json:"info"`
+		Tags       []openAPITag                    `json:"tags"`
+		Paths      map[string]map[string]openAPIOp `json:"paths"`
+		Components openAPIComponents               `json:"components"`
+		Security   []map[string][]string           `json:"security"`
+	}
+
+	openAPIInfo struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		Version     string `json:"version"`
+	}
+
+	openAPITag struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+
+	openAPIOp struct {
+		OperationID string                     `json:"operationId"`
+		Summary     string                     `json:"summary"`
+		Description string                     `json:"description,omitempty"`
+		Tags        []string                   `json:"tags"`
+		Parameters  []openAPIParam             `json:"parameters,omitempty"`
+		RequestBody *openAPIBody               `json:"requestBody,omitempty"`
+		Responses   map[string]openAPIResponse `json:"responses"`
+		Deprecated  bool                       `json:"deprecated,omitempty"`
+		// Security is set to an empty list on operations that don't need an
+		// API key, overriding the document's default.
+		Security *[]map[string][]string `json:"security,omitempty"`
+	}
+
+	openAPIParam struct {
+		Name        string         `json:"name"`
+		In          string         `json:"in"`
+		Description string         `json:"description"`
+		Required    bool           `json:"required,omitempty"`
+		Style       string         `json:"style,omitempty"`
+		Explode     *bool          `json:"explode,omitempty"`
+		Schema      *openAPISchema `json:"schema"`
+	}
+
+	openAPIBody struct {
+		Required bool                    `json:"required"`
+		Content  map[string]openAPIMedia `json:"content"`
+	}
+
+	openAPIResponse struct {
+		Description string                  `json:"description"`
+		Headers     map[string]openAPIRef   `json:"headers,omitempty"`
+		Content     map[string]openAPIMedia `json:"content,omitempty"`
+	}
+
+	openAPIRef struct {
+		Ref string `json:"$ref"`
+	}
+
+	openAPIMedia struct {
+		Schema *openAPISchema `json:"schema"`
+	}
+
+	openAPIComponents struct {
+		Schemas         map[string]*openAPISchema        `json:"schemas"`
+		Headers         map[string]openAPIHeader         `json:"headers"`
+		SecuritySchemes map[string]openAPISecurityScheme `json:"securitySchemes"`
+	}
+
+	openAPIHeader struct {
+		Description string         `json:"description"`
+		Schema      *openAPISchema `json:"schema"`
+	}
+
+	openAPISecurityScheme struct {
+		Type   string `json:"type"`
+		In     string `json:"in,omitempty"`
+		Name   string `json:"name,omitempty"`
+		Scheme string `json:"scheme,omitempty"`
+	}
+
+	openAPISchema struct {
+		Ref                  string                    `json:"$ref,omitempty"`
+		Type                 string                    `json:"type,omitempty"`
+		Format               string                    `json:"format,omitempty"`
+		Description          string                    `json:"description,omitempty"`
+		Enum                 []string                  `json:"enum,omitempty"`
+		Nullable             bool                      `json:"nullable,omitempty"`
+		Items                *openAPISchema            `json:"items,omitempty"`
+		Properties           map[string]*openAPISchema `json:"properties,omitempty"`
+		Required             []string                  `json:"required,omitempty"`
+		AdditionalProperties *openAPISchema            `json:"additionalProperties,omitempty"`
+	}
+)
+
+// apiOperation describes one route for the OpenAPI document. Body and the
+// values in Content are samples of what's sent and returned; their schemas
+// are generated from the Go types.
+type apiOperation struct {
+	Method      string
+	Path        string
+	ID          string
+	Tag         string
+	Summary     string
+	Description string
+	Params      []openAPIParam
+	Body        interface{}
+	Status      int
+	Content     map[string]interface{}
+	// V1Content replaces Content for version 1 where its representation
+	// differs.
+	V1Content map[string]interface{}
+	// Alternatives lists other statuses that also respond with Content.
+	Alternatives []int
+	// Errors lists the problem statuses besides 401 and 500, which every
+	// authenticated operation can return.
+	Errors []int
+	// Public operations don't need an API key.
+	Public bool
+	// Unversioned operations are only served at the root.
+	Unversioned bool
+	// Database operations are only served with a database.
+	Database bool
+	// Deprecated operations send the Deprecation, Sunset and Link headers.
+	Deprecated bool
+	// Cached operations send validators and answer conditional requests.
+	Cached bool
+}
+
+var apiTags = []openAPITag{
+	{Name: "waybills", Description: "Shipments and everything derived from their sightings."},
+	{Name: "tracking", Description: "Finding shipments by the references customers quote."},
+	{Name: "events", Description: "Sightings of equipment reported by railroads."},
+	{Name: "equipment", Description: "Fleet membership of cars."},
+	{Name: "locations", Description: "Stations sightings are reported at."},
+	{Name: "demurrage", Description: "Charges for cars held at customer facilities."},
+	{Name: "subscriptions", Description: "Webhooks notifying customers of new events."},
+	{Name: "alerts", Description: "Shipments that need attention."},
+	{Name: "graphql", Description: "Nested views of waybills, events, equipment and locations in one request."},
+	{Name: "docs", Description: "This document."},
+	{Name: "operations", Description: "Monitoring the service."},
+}
+
+var schemaDescriptions = map[string]string{
+	"Waybill":             "A shipment of one car, with the related resources asked for with include under included. With Telegraph-Version 1, routes and parties are JSON strings.",
+	"Event":               "A sighting of a car reported by a railroad.",
+	"Equipment":           "A car's membership of a customer's fleet. A missing date_removed means it's still a member.",
+	"Location":            "A station sightings are reported at.",
+	"RoutePart":           "One railroad on a waybill's route and the junction where it hands the car over.",
+	"Party":               "A party to a waybill, such as the shipper or consignee.",
+	"WaybillIncludes":     "A waybill's related resources. Resources that weren't asked for are left out.",
+	"Distance":            "How far a waybill's car moved, in miles.",
+	"EquipmentDistance":   "How far a car moved across its waybills, in miles.",
+	"FeatureCollection":   "A GeoJSON FeatureCollection.",
+	"Feature":             "A GeoJSON Feature. The kind property tells sightings, tracks and origin/destination markers apart.",
+	"Geometry":            "A GeoJSON Point ([lon, lat]) or LineString ([[lon, lat], ...]).",
+	"WaybillDemurrage":    "The demurrage owed on a waybill's placements.",
+	"Placement":           "One stay of a car at a facility. released_at is null while the car is still placed.",
+	"DemurrageReport":     "A month's demurrage bill per customer.",
+	"CustomerDemurrage":   "One customer's bill for a month.",
+	"Subscription":        "A webhook subscription. secret is only returned when it's created.",
+	"SubscriptionRequest": "A webhook subscription to create. Empty filters match everything.",
+	"Delivery":            "An event queued for a subscription, with a log of each attempt.",
+	"DeliveryAttempt":     "A single POST of a delivery.",
+	"DeadLetter":          "A delivery that exhausted its retries.",
+	"Alert":               "A rule firing for a waybill.",
+	"WaybillV1":           "A waybill as version 1 serves it, with routes and parties as JSON-encoded strings.",
+	"Tracking":            "The shipment a reference means, and every waybill matching it.",
+	"TrackingV1":          "A Tracking as version 1 serves it.",
+	"TrackMatch":          "A waybill matching a tracking reference.",
+	"Problem":             "An RFC 7807 problem. code is stable and meant for clients to branch on.",
+	"GraphQLRequest":      "A GraphQL query, with the operation to run when it has several and its variables.",
+	"GraphQLResponse":     "A GraphQL result. Errors carry a code in their extensions.",
+	"GraphQLError":        "An error running a GraphQL query.",
+	"FieldError":          "One invalid request parameter.",
+}
+
+// schemaNames renames types whose Go name isn't what clients know them as.
+var schemaNames = map[reflect.Type]string{
+	// The API serves waybills as details; the stored Waybill isn't served.
+	reflect.TypeOf(WaybillDetail{}):            "Waybill",
+	reflect.TypeOf(legacyWaybillDetail{}):      "WaybillV1",
+	reflect.TypeOf(legacyTracking{}):           "TrackingV1",
+	reflect.TypeOf(graphQLRequest{}):           "GraphQLRequest",
+	reflect.TypeOf(graphql.Result{}):           "GraphQLResponse",
+	reflect.TypeOf(gqlerrors.FormattedError{}): "GraphQLError",
+}
+
+// legacyTracking describes a Tracking as version 1 serves it. WaybillDetail
+// marshals itself per version, so it's only needed for the schema.
+type legacyTracking struct {
+	Tracking
+	Waybill *legacyWaybillDetail `json:"waybill,omitempty"`
+}
+
+var problemDescriptions = map[int]string{
+	http.StatusBadRequest:          "The request has invalid parameters or a malformed body.",
+	http.StatusUnauthorized:        "The API key is missing, unknown or revoked.",
+	http.StatusForbidden:           "The API key can't act for the requested customer.",
+	http.StatusNotFound:            "The resource doesn't exist or isn't visible to the API key.",
+	http.StatusConflict:            "The resource can't move to the requested state.",
+	http.StatusInternalServerError: "The server could not complete the request.",
+}
+
+func queryParam(name, description string, schema *openAPISchema) openAPIParam {
+	return openAPIParam{Name: name, In: "query", Description: description, Schema: schema}
+}
+
+var (
+	stringSchema   = &openAPISchema{Type: "string"}
+	dateTimeSchema = &openAPISchema{Type: "string", Format: "date-time"}
+
+	waybillIDParam   = openAPIParam{Name: "id", In: "path", Description: "Waybill ID.", Required: true, Schema: stringSchema}
+	equipmentIDParam = openAPIParam{Name: "id", In: "path", Description: "Equipment ID.", Required: true, Schema: stringSchema}
+	numericIDParam   = openAPIParam{Name: "id", In: "path", Description: "Numeric ID.", Required: true, Schema: &openAPISchema{Type: "integer", Format: "int64"}}
+	afterParam       = queryParam("after", "Only events posted after this RFC3339 timestamp.", dateTimeSchema)
+	asOfParam        = queryParam("as_of", "RFC3339 timestamp to assess at. Defaults to now.", dateTimeSchema)
+	includeParam     = openAPIParam{
+		Name: "include", In: "query", Description: "Related resources to embed, comma-separated.", Style: "form", Explode: new(bool),
+		Schema: &openAPISchema{Type: "array", Items: &openAPISchema{Type: "string", Enum: waybillIncludes}},
+	}
+	versionParam = openAPIParam{
+		Name: VersionHeader, In: "header", Schema: &openAPISchema{Type: "string", Enum: []string{"1", "2"}},
+		Description: "1 (the default) serves waybill routes and parties as the JSON strings they're stored as, 2 as arrays. Responses echo the version served.",
+	}
+	lastEventParam   = openAPIParam{Name: "Last-Event-ID", In: "header", Description: "The id of the last event received, to resume a stream. Takes precedence over after.", Schema: stringSchema}
+	ifNoneMatchParam = openAPIParam{Name: "If-None-Match", In: "header", Description: "ETags of cached copies. Responds 304 if one is current.", Schema: stringSchema}
+	ifModifiedParam  = openAPIParam{Name: "If-Modified-Since", In: "header", Description: "Responds 304 if the data hasn't been ingested since. Ignored with If-None-Match.", Schema: stringSchema}
+)
+
+const exportDescription = "Send `Accept: text/csv` for a CSV file with the columns ingestion reads, or `Accept: application/x-ndjson` for an item per line. " +
+	"Both are streamed as rows are read."
+
+const streamDescription = "Pushes newly posted events as server-sent events. Each `event` message carries an Event as data and a `<posting_date>/<id>` cursor as its id; " +
+	"a stream that fails sends a final `error` message carrying a Problem. The stream starts from the time of the request unless after or Last-Event-ID is sent."
+
+// apiOperations describes every route registered by routes().
+func (h *HTTP) apiOperations() []apiOperation {
+	waybillOp := func(path, id, summary string, content map[string]interface{}) apiOperation {
+		return apiOperation{
+			Method: http.MethodGet, Path: path, ID: id, Tag: "waybills", Summary: summary,
+			Params: []openAPIParam{waybillIDParam}, Content: content, Errors: []int{http.StatusNotFound}, Cached: true,
+		}
+	}
+	jsonContent := func(v interface{}) map[string]interface{} {
+		return map[string]interface{}{"application/json": v}
+	}
+	// exportContent is a list's JSON content along with its exports.
+	exportContent := func(list, item interface{}) map[string]interface{} {
+		return map[string]interface{}{"application/json": list, csvMediaType: "", ndjsonMediaType: item}
+	}
+	exportOp := func(op apiOperation) apiOperation {
+		op.Description = strings.TrimSpace(op.Description + " " + exportDescription)
+		return op
+	}
+
+	return []apiOperation{
+		{
+			Method: http.MethodGet, Path: "/openapi.json", ID: "getOpenAPI", Tag: "docs",
+			Summary: "This OpenAPI document", Content: jsonContent(map[string]interface{}{}), Public: true,
+		},
+		{
+			Method: http.MethodGet, Path: "/docs", ID: "getDocs", Tag: "docs",
+			Summary: "Browsable API documentation", Content: map[string]interface{}{"text/html": ""}, Public: true,
+		},
+		{
+			Method: http.MethodGet, Path: "/metrics", ID: "getMetrics", Tag: "operations",
+			Summary:     "Read cache counters",
+			Description: "Hits, misses and errors of the read cache per kind of read, in Prometheus's text format.",
+			Content:     map[string]interface{}{"text/plain": ""}, Public: true,
+		},
+		{
+			Method: http.MethodGet, Path: "/healthz", ID: "getHealthz", Tag: "operations",
+			Summary:     "Check the server is up",
+			Description: "For liveness probes. It doesn't check the database.",
+			Content:     jsonContent(Health{}), Public: true,
+		},
+		{
+			Method: http.MethodGet, Path: "/readyz", ID: "getReadyz", Tag: "operations",
+			Summary:     "Check the server is ready for traffic",
+			Description: "For readiness probes. Responds 503 while shutting down, or when the database can't be reached, its migrations aren't current, or the data is older than the configured maximum ingest age.",
+			Content:     jsonContent(Readiness{}), Alternatives: []int{http.StatusServiceUnavailable}, Public: true,
+		},
+		exportOp(apiOperation{
+			Method: http.MethodGet, Path: "/equipment", ID: "listEquipment", Tag: "equipment",
+			Summary: "List fleet membership records",
+			Params:  []openAPIParam{queryParam("as_of", "List the fleet as it was at this RFC3339 timestamp.", dateTimeSchema)},
+			Content: exportContent([]Equipment{}, Equipment{}), Errors: []int{http.StatusBadRequest}, Cached: true,
+		}),
+		{
+			Method: http.MethodGet, Path: "/equipment/:id/distance", ID: "getEquipmentDistance", Tag: "equipment",
+			Summary:     "Get how far a car moved",
+			Description: "Sums the distances of the car's waybills, which are listed with their own distances.",
+			Params:      []openAPIParam{equipmentIDParam}, Content: jsonContent(EquipmentDistance{}), Errors: []int{http.StatusNotFound}, Cached: true,
+		},
+		exportOp(apiOperation{
+			Method: http.MethodGet, Path: "/events", ID: "listEvents", Tag: "events",
+			Summary: "List events", Params: []openAPIParam{afterParam},
+			Content: exportContent([]Event{}, Event{}), Errors: []int{http.StatusBadRequest}, Cached: true,
+		}),
+		{
+			Method: http.MethodGet, Path: "/events/stream", ID: "streamEvents", Tag: "events",
+			Summary: "Stream new events", Description: streamDescription,
+			Params:  []openAPIParam{afterParam, lastEventParam},
+			Content: map[string]interface{}{"text/event-stream": ""}, Errors: []int{http.StatusBadRequest},
+		},
+		{
+			Method: http.MethodGet, Path: "/locations", ID: "listLocations", Tag: "locations",
+			Summary:     "List locations",
+			Description: "Send `Accept: application/geo+json` for a point FeatureCollection. " + exportDescription,
+			Content: map[string]interface{}{
+				"application/json": []Location{}, geoJSONMediaType: FeatureCollection{}, csvMediaType: "", ndjsonMediaType: Location{},
+			},
+			Cached: true,
+		},
+		{
+			Method: http.MethodGet, Path: "/locations.geojson", ID: "listLocationsGeoJSON", Tag: "locations",
+			Summary: "List locations as GeoJSON", Content: map[string]interface{}{geoJSONMediaType: FeatureCollection{}}, Cached: true,
+		},
+		exportOp(apiOperation{
+			Method: http.MethodGet, Path: "/waybills", ID: "listWaybills", Tag: "waybills",
+			Summary:     "List waybills",
+			Description: "CSV exports keep routes and parties as JSON strings in every version, and can't include resources.",
+			Params: []openAPIParam{
+				queryParam("waybill_number", "Only waybills with this number.", stringSchema),
+				queryParam("bill_of_lading_number", "Only waybills with this bill of lading number.", stringSchema),
+				queryParam("equipment_id", "Only waybills of this car.", stringSchema),
+				includeParam,
+				versionParam,
+			},
+			Content: exportContent([]WaybillDetail{}, WaybillDetail{}), V1Content: exportContent([]legacyWaybillDetail{}, legacyWaybillDetail{}),
+			Errors: []int{http.StatusBadRequest}, Cached: true,
+		}),
+		{
+			Method: http.MethodGet, Path: "/waybills/:id", ID: "getWaybill", Tag: "waybills",
+			Summary: "Get a waybill", Params: []openAPIParam{waybillIDParam, includeParam, versionParam},
+			Content: jsonContent(WaybillDetail{}), V1Content: jsonContent(legacyWaybillDetail{}),
+			Errors: []int{http.StatusBadRequest, http.StatusNotFound}, Cached: true,
+		},
+		exportOp(waybillOp("/waybills/:id/equipment", "getWaybillEquipment", "Get the fleet record in effect for a waybill", exportContent([]Equipment{}, Equipment{}))),
+		exportOp(apiOperation{
+			Method: http.MethodGet, Path: "/waybills/:id/events", ID: "listWaybillEvents", Tag: "waybills",
+			Summary: "List a waybill's events", Params: []openAPIParam{waybillIDParam, afterParam},
+			Content: exportContent([]Event{}, Event{}), Errors: []int{http.StatusBadRequest, http.StatusNotFound}, Cached: true,
+		}),
+		{
+			Method: http.MethodGet, Path: "/waybills/:id/events/stream", ID: "streamWaybillEvents", Tag: "waybills",
+			Summary: "Stream a waybill's new events", Description: streamDescription,
+			Params:  []openAPIParam{waybillIDParam, afterParam, lastEventParam},
+			Content: map[string]interface{}{"text/event-stream": ""}, Errors: []int{http.StatusBadRequest, http.StatusNotFound},
+		},
+		exportOp(waybillOp("/waybills/:id/locations", "listWaybillLocations", "List the locations a waybill's car was sighted at", exportContent([]Location{}, Location{}))),
+		waybillOp("/waybills/:id/route", "getWaybillRoute", "Get a waybill's route", jsonContent([]RoutePart{})),
+		waybillOp("/waybills/:id/parties", "getWaybillParties", "Get a waybill's parties", jsonContent([]Party{})),
+		waybillOp("/waybills/:id/distance", "getWaybillDistance", "Get how far a waybill's car moved", jsonContent(Distance{})),
+		waybillOp("/waybills/:id/track", "getWaybillTrack", "Get a waybill's track as GeoJSON", map[string]interface{}{geoJSONMediaType: FeatureCollection{}}),
+		waybillOp("/waybills/:id/track.geojson", "getWaybillTrackGeoJSON", "Get a waybill's track as GeoJSON", map[string]interface{}{geoJSONMediaType: FeatureCollection{}}),
+		{
+			Method: http.MethodGet, Path: "/waybills/:id/demurrage", ID: "getWaybillDemurrage", Tag: "demurrage",
+			Summary: "Price a waybill's placements", Params: []openAPIParam{waybillIDParam, asOfParam},
+			Content: jsonContent(WaybillDemurrage{}), Errors: []int{http.StatusBadRequest, http.StatusNotFound},
+		},
+		{
+			Method: http.MethodGet, Path: "/reports/demurrage", ID: "getDemurrageReport", Tag: "demurrage",
+			Summary:     "Bill each customer for a month",
+			Description: "Send `format=csv` or `Accept: text/csv` for a billing export.",
+			Params: []openAPIParam{
+				{Name: "month", In: "query", Description: "The month to bill, as YYYY-MM.", Required: true, Schema: stringSchema},
+				asOfParam,
+				queryParam("customer", "Only bill this customer.", stringSchema),
+				queryParam("format", "csv for a billing export.", &openAPISchema{Type: "string", Enum: []string{"csv"}}),
+			},
+			Content: map[string]interface{}{"application/json": DemurrageReport{}, "text/csv": ""},
+			Errors:  []int{http.StatusBadRequest},
+		},
+		{
+			Method: http.MethodGet, Path: "/track/:reference", ID: "track", Tag: "tracking",
+			Summary: "Find the shipment a reference means",
+			Description: "Matches the reference as a waybill number, then a bill of lading number, then an equipment ID, and selects the latest " +
+				"matching waybill dated on or before as_of with its latest event. Responds 300 with no waybill selected when several share that date.",
+			Params: []openAPIParam{
+				{Name: "reference", In: "path", Description: "A waybill number, bill of lading number or equipment ID.", Required: true, Schema: stringSchema},
+				asOfParam,
+				versionParam,
+			},
+			Content: jsonContent(Tracking{}), V1Content: jsonContent(legacyTracking{}), Alternatives: []int{http.StatusMultipleChoices},
+			Errors: []int{http.StatusBadRequest, http.StatusNotFound},
+		},
+		{
+			Method: http.MethodPost, Path: "/graphql", ID: "graphql", Tag: "graphql",
+			Summary: "Run a GraphQL query",
+			Description: "Introspect the schema for its types. Fields are named as in the REST representations, and a relation resolved for many " +
+				"parents is read with one query. Queries nesting too deep or estimated to resolve too many fields, counting each list as 10 items, " +
+				"are rejected with a query_too_deep or query_too_complex error.",
+			Body: graphQLRequest{}, Content: jsonContent(graphql.Result{}), Errors: []int{http.StatusBadRequest}, Unversioned: true,
+		},
+		{
+			Method: http.MethodPost, Path: "/subscriptions", ID: "createSubscription", Tag: "subscriptions",
+			Summary: "Subscribe to events", Body: subscriptionRequest{},
+			Status: http.StatusCreated, Content: jsonContent(Subscription{}),
+			Errors: []int{http.StatusBadRequest, http.StatusForbidden}, Database: true,
+		},
+		{
+			Method: http.MethodGet, Path: "/subscriptions", ID: "listSubscriptions", Tag: "subscriptions",
+			Summary: "List subscriptions", Content: jsonContent([]Subscription{}), Database: true,
+		},
+		{
+			Method: http.MethodGet, Path: "/subscriptions/:id", ID: "getSubscription", Tag: "subscriptions",
+			Summary: "Get a subscription", Params: []openAPIParam{numericIDParam},
+			Content: jsonContent(Subscription{}), Errors: []int{http.StatusBadRequest, http.StatusNotFound}, Database: true,
+		},
+		{
+			Method: http.MethodDelete, Path: "/subscriptions/:id", ID: "deleteSubscription", Tag: "subscriptions",
+			Summary: "Unsubscribe", Description: "Pending deliveries are dropped.", Params: []openAPIParam{numericIDParam},
+			Status: http.StatusNoContent, Errors: []int{http.StatusBadRequest, http.StatusNotFound}, Database: true,
+		},
+		{
+			Method: http.MethodGet, Path: "/subscriptions/:id/deliveries", ID: "listSubscriptionDeliveries", Tag: "subscriptions",
+			Summary: "List a subscription's deliveries", Params: []openAPIParam{numericIDParam},
+			Content: jsonContent([]Delivery{}), Errors: []int{http.StatusBadRequest, http.StatusNotFound}, Database: true,
+		},
+		{
+			Method: http.MethodGet, Path: "/subscriptions/:id/dead-letters", ID: "listSubscriptionDeadLetters", Tag: "subscriptions",
+			Summary: "List a subscription's dead letters", Params: []openAPIParam{numericIDParam},
+			Content: jsonContent([]DeadLetter{}), Errors: []int{http.StatusBadRequest, http.StatusNotFound}, Database: true,
+		},
+		{
+			Method: http.MethodGet, Path: "/alerts", ID: "listAlerts", Tag: "alerts",
+			Summary: "List alerts",
+			Params: []openAPIParam{
+				queryParam("status", "Only alerts in this status.", &openAPISchema{Type: "string", Enum: []string{AlertOpen, AlertAcknowledged, AlertResolved}}),
+				queryParam("rule", "Only alerts raised by this rule.", stringSchema),
+				queryParam("waybill_id", "Only alerts for this waybill.", stringSchema),
+			},
+			Content: jsonContent([]Alert{}), Errors: []int{http.StatusBadRequest}, Database: true,
+		},
+		{
+			Method: http.MethodGet, Path: "/alerts/:id", ID: "getAlert", Tag: "alerts",
+			Summary: "Get an alert", Params: []openAPIParam{numericIDParam},
+			Content: jsonContent(Alert{}), Errors: []int{http.StatusBadRequest, http.StatusNotFound}, Database: true,
+		},
+		{
+			Method: http.MethodPost, Path: "/alerts/:id/acknowledge", ID: "acknowledgeAlert", Tag: "alerts",
+			Summary: "Acknowledge an open alert", Params: []openAPIParam{numericIDParam},
+			Content: jsonContent(Alert{}), Errors: []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict}, Database: true,
+		},
+		{
+			Method: http.MethodPost, Path: "/alerts/:id/resolve", ID: "resolveAlert", Tag: "alerts",
+			Summary: "Resolve an open or acknowledged alert", Params: []openAPIParam{numericIDParam},
+			Content: jsonContent(Alert{}), Errors: []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict}, Database: true,
+		},
+	}
+}
+
+// openAPI builds the OpenAPI document for the routes this HTTP serves.
+func (h *HTTP) openAPI() openAPIDoc {
+	schemas := make(map[string]*openAPISchema)
+	doc := openAPIDoc{
+		OpenAPI: openAPIVersion,
+		Info: openAPIInfo{
+			Title: "Telegraph API",
+			Description: "Tracks rail shipments from waybills and the sightings railroads report. " +
+				"Every error is an RFC 7807 Problem, and every response carries an X-Request-ID header. " +
+				"Routes are served under /v2; /v1 keeps version 1's representations, and the unversioned routes serve the version sent in Telegraph-Version, 1 by default. " +
+				"The unversioned routes are deprecated and announce when they'll be removed with the Sunset header.",
+			Version: apiVersion,
+		},
+		Tags:  apiTags,
+		Paths: make(map[string]map[string]openAPIOp),
+		Components: openAPIComponents{
+			Schemas: schemas,
+			Headers: map[string]openAPIHeader{
+				RequestIDHeader:   {Description: "Identifies the request in the server's logs. Echoes the request's header when it sent a valid one.", Schema: stringSchema},
+				DeprecationHeader: {Description: "When the route was deprecated, as an RFC 9745 date such as @1792368000.", Schema: stringSchema},
+				SunsetHeader:      {Description: "The HTTP date after which the route may be removed.", Schema: stringSchema},
+				LinkHeader:        {Description: "The route replacing this one, with rel=\"successor-version\".", Schema: stringSchema},
+				"ETag":            {Description: "Identifies the response as of the latest ingest run of the data it's built from.", Schema: stringSchema},
+				"Last-Modified":   {Description: "When the data the response is built from was last ingested.", Schema: stringSchema},
+				"Cache-Control":   {Description: "How long the response may be reused without revalidating it.", Schema: stringSchema},
+			},
+			SecuritySchemes: map[string]openAPISecurityScheme{
+				"apiKey": {Type: "apiKey", In: "header", Name: "X-API-Key"},
+				"bearer": {Type: "http", Scheme: "bearer"},
+			},
+		},
+		Security: []map[string][]string{{"apiKey": {}}, {"bearer": {}}},
+	}
+
+	for _, op := range h.apiOperations() {
+		if op.Database && h.db == nil {
+			continue
+		}
+		if op.Public || op.Unversioned {
+			doc.addOperation(op, schemas)
+			continue
+		}
+		for _, g := range apiGroups {
+			doc.addOperation(versionedOperation(op, g), schemas)
+		}
+	}
+
+	return doc
+}
+
+// versionedOperation describes op as served under an apiGroup's prefix.
+func versionedOperation(op apiOperation, g apiGroup) apiOperation {
+	op.Path = g.Prefix + op.Path
+	op.Deprecated = g.Deprecation != nil
+	if g.Version == 0 {
+		return op
+	}
+
+	op.ID += "V" + strconv.Itoa(g.Version)
+	// The prefix selects the version instead of the header.
+	var params []openAPIParam
+	for _, p := range op.Params {
+		if p.Name != VersionHeader {
+			params = append(params, p)
+		}
+	}
+	op.Params = params
+	if g.Version == APIVersion1 && op.V1Content != nil {
+		op.Content = op.V1Content
+	}
+	return op
+}
+
+// addOperation adds op to the document, generating the schemas it uses.
+func (doc *openAPIDoc) addOperation(op apiOperation, schemas map[string]*openAPISchema) {
+	headers := requestIDHeaders()
+	if op.Deprecated {
+		for _, name := range []string{DeprecationHeader, SunsetHeader, LinkHeader} {
+			headers[name] = openAPIRef{Ref: "#/components/headers/" + name}
+		}
+	}
+
+	// Problems don't carry validators.
+	problemHeaders := headers
+	if op.Cached {
+		headers = make(map[string]openAPIRef)
+		for name, ref := range problemHeaders {
+			headers[name] = ref
+		}
+		for _, name := range []string{"ETag", "Last-Modified", "Cache-Control"} {
+			headers[name] = openAPIRef{Ref: "#/components/headers/" + name}
+		}
+		op.Params = append(append([]openAPIParam(nil), op.Params...), ifNoneMatchParam, ifModifiedParam)
+	}
+
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := openAPIResponse{Description: http.StatusText(status), Headers: headers}
+	for media, v := range op.Content {
+		if success.Content == nil {
+			success.Content = make(map[string]openAPIMedia)
+		}
+		success.Content[media] = openAPIMedia{Schema: schemaOf(reflect.TypeOf(v), schemas)}
+	}
+
+	responses := map[string]openAPIResponse{strconv.Itoa(status): success}
+	for _, s := range op.Alternatives {
+		alternative := success
+		alternative.Description = http.StatusText(s)
+		responses[strconv.Itoa(s)] = alternative
+	}
+	if op.Cached {
+		responses[strconv.Itoa(http.StatusNotModified)] = openAPIResponse{Description: "The cached copy is current.", Headers: headers}
+	}
+
+	operation := openAPIOp{
+		OperationID: op.ID,
+		Summary:     op.Summary,
+		Description: op.Description,
+		Tags:        []string{op.Tag},
+		Parameters:  op.Params,
+		Responses:   responses,
+		Deprecated:  op.Deprecated,
+	}
+	if op.Body != nil {
+		operation.RequestBody = &openAPIBody{
+			Required: true,
+			Content:  map[string]openAPIMedia{"application/json": {Schema: schemaOf(reflect.TypeOf(op.Body), schemas)}},
+		}
+	}
+
+	errs := append([]int{}, op.Errors...)
+	if op.Public {
+		operation.Security = &[]map[string][]string{}
+	} else {
+		errs = append(errs, http.StatusUnauthorized)
+	}
+	errs = append(errs, http.StatusInternalServerError)
+	problem := schemaOf(reflect.TypeOf(Problem{}), schemas)
+	for _, s := range errs {
+		operation.Responses[strconv.Itoa(s)] = openAPIResponse{
+			Description: problemDescriptions[s],
+			Headers:     problemHeaders,
+			Content:     map[string]openAPIMedia{problemContentType: {Schema: problem}},
+		}
+	}
+
+	path := openAPIPath(op.Path)
+	if doc.Paths[path] == nil {
+		doc.Paths[path] = make(map[string]openAPIOp)
+	}
+	doc.Paths[path][strings.ToLower(op.Method)] = operation
+}
+
+// OpenAPISpec serves the OpenAPI document describing the API.
+func (h *HTTP) OpenAPISpec() gin.HandlerFunc {
+	doc := h.openAPI()
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, doc)
+	}
+}
+
+// Docs serves a page rendering the OpenAPI document.
+func (h *HTTP) Docs() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", docsPage)
+	}
+}
+
+func requestIDHeaders() map[string]openAPIRef {
+	return map[string]openAPIRef{RequestIDHeader: {Ref: "#/components/headers/" + RequestIDHeader}}
+}
+
+// openAPIPath turns a gin route's :params into OpenAPI {params}.
+func openAPIPath(route string) string {
+	parts := strings.Split(route, "/")
+	for k, part := range parts {
+		if strings.HasPrefix(part, ":") {
+			parts[k] = "{" + part[1:] + "}"
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// addProperties adds the JSON fields of a struct to s. The fields of embedded
+// structs are promoted unless a shallower field has the same name, as
+// encoding/json does.
+func addProperties(s *openAPISchema, t reflect.Type, schemas map[string]*openAPISchema) {
+	var embedded []reflect.Type
+	for k := 0; k < t.NumField(); k++ {
+		f := t.Field(k)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		if f.Anonymous && tag == "" && f.Type.Kind() == reflect.Struct {
+			embedded = append(embedded, f.Type)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+
+		field, opts, _ := strings.Cut(tag, ",")
+		if field == "" {
+			field = f.Name
+		}
+		if _, ok := s.Properties[field]; ok {
+			continue
+		}
+		s.Properties[field] = schemaOf(f.Type, schemas)
+		if !strings.Contains(opts, "omitempty") {
+			s.Required = append(s.Required, field)
+		}
+	}
+
+	for _, e := range embedded {
+		addProperties(s, e, schemas)
+	}
+}
+
+// schemaOf generates the schema of a JSON-encoded Go type. Named structs are
+// added to schemas and referenced, so each model is described once.
+func schemaOf(t reflect.Type, schemas map[string]*openAPISchema) *openAPISchema {
+	if t == reflect.TypeOf(time.Time{}) {
+		return &openAPISchema{Type: "string", Format: "date-time"}
+	}
+	if t == reflect.TypeOf(Cents(0)) {
+		return &openAPISchema{Type: "number"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		s := schemaOf(t.Elem(), schemas)
+		if s.Ref == "" {
+			s.Nullable = true
+		}
+		return s
+	case reflect.String:
+		return &openAPISchema{Type: "string"}
+	case reflect.Bool:
+		return &openAPISchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &openAPISchema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &openAPISchema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &openAPISchema{Type: "number", Format: "double"}
+	case reflect.Slice, reflect.Array:
+		return &openAPISchema{Type: "array", Items: schemaOf(t.Elem(), schemas)}
+	case reflect.Map:
+		return &openAPISchema{Type: "object", AdditionalProperties: schemaOf(t.Elem(), schemas)}
+	case reflect.Struct:
+		name := []rune(t.Name())
+		if rename, ok := schemaNames[t]; ok {
+			name = []rune(rename)
+		}
+		name[0] = unicode.ToUpper(name[0])
+		ref := &openAPISchema{Ref: "#/components/schemas/" + string(name)}
+		if _, ok := schemas[string(name)]; ok {
+			return ref
+		}
+
+		s := &openAPISchema{Type: "object", Description: schemaDescriptions[string(name)], Properties: make(map[string]*openAPISchema)}
+		// Registered before the fields so recursive types terminate.
+		schemas[string(name)] = s
+		addProperties(s, t, schemas)
+		sort.Strings(s.Required)
+		return ref
+	default:
+		// interface{} can hold anything.
+		return &openAPISchema{}
+	}
+}
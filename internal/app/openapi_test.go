@@ -0,0 +1,165 @@
+package app_test
+
+import (
+	"encoding/json"
+	"github.com/coreyvan/backend-takehome/internal/app"
+	"github.com/coreyvan/backend-takehome/internal/config"
+	"github.com/coreyvan/backend-takehome/internal/database"
+	"go.uber.org/zap"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// spec is the part of the OpenAPI document the tests check.
+type spec struct {
+	Paths map[string]map[string]struct {
+		Parameters []struct {
+			Name string `json:"name"`
+			In   string `json:"in"`
+		} `json:"parameters"`
+		Responses map[string]struct {
+			Content map[string]json.RawMessage `json:"content"`
+		} `json:"responses"`
+	} `json:"paths"`
+}
+
+var pathParam = regexp.MustCompile(`\{([^}]+)\}`)
+
+// TestOpenAPIMatchesRouter checks /openapi.json describes exactly the routes
+// the router serves, with and without a database, and declares every path
+// param.
+func TestOpenAPIMatchesRouter(t *testing.T) {
+	for name, h := range map[string]*app.HTTP{
+		"database": newDatabaseHTTP(t),
+		"memory":   app.NewHTTP(zap.NewNop(), config.Default().HTTP, app.NewMemoryStore(nil, nil, nil, nil).Stores(), nil),
+	} {
+		t.Run(name, func(t *testing.T) {
+			doc := fetchSpec(t, h)
+
+			routes := make(map[string]bool)
+			for _, r := range h.Routes() {
+				routes[r.Method+" "+openAPIPath(r.Path)] = true
+			}
+
+			documented := make(map[string]bool)
+			for path, item := range doc.Paths {
+				for method, op := range item {
+					route := strings.ToUpper(method) + " " + path
+					documented[route] = true
+					if !routes[route] {
+						t.Errorf("%s is documented but not routed", route)
+					}
+
+					var want, got []string
+					for _, m := range pathParam.FindAllStringSubmatch(path, -1) {
+						want = append(want, m[1])
+					}
+					for _, p := range op.Parameters {
+						if p.In == "path" {
+							got = append(got, p.Name)
+						}
+					}
+					sort.Strings(want)
+					sort.Strings(got)
+					if strings.Join(got, ",") != strings.Join(want, ",") {
+						t.Errorf("%s declares path params %v, want %v", route, got, want)
+					}
+				}
+			}
+
+			for route := range routes {
+				if !documented[route] {
+					t.Errorf("%s is routed but not documented", route)
+				}
+			}
+		})
+	}
+}
+
+// TestOpenAPIDocumentsResponses checks every status and content type
+// recorded in the golden files is documented for its route.
+func TestOpenAPIDocumentsResponses(t *testing.T) {
+	doc := fetchSpec(t, newDatabaseHTTP(t))
+
+	for _, tc := range apiCases {
+		if tc.route == "" {
+			continue
+		}
+		method := tc.method
+		if method == "" {
+			method = http.MethodGet
+		}
+
+		b, err := os.ReadFile(filepath.Join("testdata", "golden", tc.name+".json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got response
+		if err := json.Unmarshal(b, &got); err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+
+		res, ok := doc.Paths[openAPIPath(tc.route)][strings.ToLower(method)].Responses[strconv.Itoa(got.Status)]
+		if !ok {
+			t.Errorf("%s: %s %s doesn't document status %d", tc.name, method, tc.route, got.Status)
+			continue
+		}
+		if got.ContentType == "" {
+			continue
+		}
+		media, _, err := mime.ParseMediaType(got.ContentType)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if _, ok := res.Content[media]; !ok {
+			t.Errorf("%s: %s %s doesn't document %s for status %d", tc.name, method, tc.route, media, got.Status)
+		}
+	}
+}
+
+func newDatabaseHTTP(t *testing.T) *app.HTTP {
+	t.Helper()
+
+	cfg := config.Default()
+	cfg.DB.DSN = "sqlite://" + filepath.Join(t.TempDir(), "openapi.db")
+	db, err := database.Open(cfg.DB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return app.NewHTTP(zap.NewNop(), cfg.HTTP, app.NewGormStore(db).Stores(), db)
+}
+
+func fetchSpec(t *testing.T, h *app.HTTP) spec {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	h.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /openapi.json = %d: %s", rec.Code, rec.Body)
+	}
+
+	var doc spec
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+// openAPIPath turns a gin route's :params into OpenAPI {params}.
+func openAPIPath(route string) string {
+	parts := strings.Split(route, "/")
+	for k, part := range parts {
+		if strings.HasPrefix(part, ":") {
+			parts[k] = "{" + part[1:] + "}"
+		}
+	}
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,154 @@
+package app
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+const (
+	problemContentType = "application/problem+json"
+	problemTypePrefix  = "urn:telegraph:problem:"
+
+	RequestIDHeader = "X-Request-ID"
+	requestIDKey    = "request_id"
+)
+
+// Machine-readable problem codes. Each code always comes with the same HTTP
+// status.
+const (
+	CodeInvalidRequest       = "invalid_request"
+	CodeMalformedBody        = "malformed_body"
+	CodeAPIKeyRequired       = "api_key_required"
+	CodeAPIKeyInvalid        = "api_key_invalid"
+	CodeCustomerForbidden    = "customer_forbidden"
+	CodeRouteNotFound        = "route_not_found"
+	CodeWaybillNotFound      = "waybill_not_found"
+	CodeEquipmentNotFound    = "equipment_not_found"
+	CodeReferenceNotFound    = "reference_not_found"
+	CodeSubscriptionNotFound = "subscription_not_found"
+	CodeAlertNotFound        = "alert_not_found"
+	CodeAlertStateConflict   = "alert_state_conflict"
+	CodeInternal             = "internal_error"
+	CodeShuttingDown         = "shutting_down"
+)
+
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// Problem is an RFC 7807 problem details response. Code identifies the kind
+// of problem and Errors lists each invalid request parameter.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError describes one invalid request parameter. In is where it was
+// sent: path, query, header or body.
+type FieldError struct {
+	Name   string `json:"name"`
+	In     string `json:"in"`
+	Reason string `json:"reason"`
+}
+
+// requestID tags each request with the X-Request-ID it was sent with, or a
+// new one, and echoes it in the response so errors can be traced in the logs.
+func requestID(c *gin.Context) {
+	id := ensureRequestID(c.GetHeader(RequestIDHeader))
+	c.Set(requestIDKey, id)
+	c.Header(RequestIDHeader, id)
+	c.Next()
+}
+
+// ensureRequestID returns the request ID a caller sent, or a new one when it
+// sent none or an invalid one.
+func ensureRequestID(id string) string {
+	if validRequestID.MatchString(id) {
+		return id
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b)
+}
+
+func (h *HTTP) newProblem(c *gin.Context, status int, code, detail string, errs ...FieldError) Problem {
+	return Problem{
+		Type:      problemTypePrefix + code,
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Instance:  c.Request.URL.Path,
+		Code:      code,
+		RequestID: c.GetString(requestIDKey),
+		Errors:    errs,
+	}
+}
+
+// problem responds with a problem and stops the handler chain.
+func (h *HTTP) problem(c *gin.Context, status int, code, detail string, errs ...FieldError) {
+	// Problems describe one request, not the resource cached() validated.
+	c.Writer.Header().Del("ETag")
+	c.Writer.Header().Del("Last-Modified")
+	c.Header("Cache-Control", "no-store")
+	c.Header("Content-Type", problemContentType)
+	c.AbortWithStatusJSON(status, h.newProblem(c, status, code, detail, errs...))
+}
+
+// invalid responds that request parameters failed validation.
+func (h *HTTP) invalid(c *gin.Context, errs ...FieldError) {
+	h.problem(c, http.StatusBadRequest, CodeInvalidRequest, "The request has invalid parameters.", errs...)
+}
+
+// internalError logs err against the request ID and responds without
+// leaking it.
+func (h *HTTP) internalError(c *gin.Context, err error) {
+	h.log.Sugar().Errorw(err.Error(), "request_id", c.GetString(requestIDKey), "path", c.Request.URL.Path)
+	h.problem(c, http.StatusInternalServerError, CodeInternal, "The server could not complete the request.")
+}
+
+func (h *HTTP) routeNotFound(c *gin.Context) {
+	h.problem(c, http.StatusNotFound, CodeRouteNotFound, "No route matches "+c.Request.Method+" "+c.Request.URL.Path+".")
+}
+
+func (h *HTTP) recovered(c *gin.Context, err interface{}) {
+	h.log.Sugar().Errorw("panic serving request", "error", err, "request_id", c.GetString(requestIDKey), "path", c.Request.URL.Path)
+	h.problem(c, http.StatusInternalServerError, CodeInternal, "The server could not complete the request.")
+}
+
+// queryTime parses an optional RFC3339 query param, returning the zero time
+// when it's absent and recording a FieldError when it's invalid.
+func queryTime(c *gin.Context, name string, errs *[]FieldError) time.Time {
+	v := c.Query(name)
+	if v == "" {
+		return time.Time{}
+	}
+
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		*errs = append(*errs, FieldError{Name: name, In: "query", Reason: "must be an RFC3339 timestamp"})
+		return time.Time{}
+	}
+	return t
+}
+
+// pathID parses a numeric ID path param, recording a FieldError when it
+// isn't one.
+func pathID(c *gin.Context, errs *[]FieldError) uint {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		*errs = append(*errs, FieldError{Name: "id", In: "path", Reason: "must be a positive integer"})
+		return 0
+	}
+	return uint(id)
+}
@@ -0,0 +1,121 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNotFound is returned by stores when a record doesn't exist or is outside
+// the caller's scope.
+var ErrNotFound = errors.New("record not found")
+
+// Scope limits reads to the cars of some customers. The zero value is
+// unrestricted.
+type Scope struct {
+	Customers  []string
+	Restricted bool
+}
+
+// Allows reports whether the scope may see a customer's data.
+func (s Scope) Allows(customer string) bool {
+	return !s.Restricted || contains(s.Customers, customer)
+}
+
+// EquipmentFilter narrows an equipment listing. Zero fields don't filter.
+type EquipmentFilter struct {
+	EquipmentIDs []string
+	// AsOf keeps the records active at that time.
+	AsOf time.Time
+}
+
+// EventFilter narrows an event listing. Zero fields don't filter.
+type EventFilter struct {
+	WaybillID   string
+	WaybillIDs  []string
+	EquipmentID string
+	Codes       []string
+	PostedAfter time.Time
+}
+
+// WaybillFilter narrows a waybill listing. Zero fields don't filter.
+type WaybillFilter struct {
+	IDs                []string
+	WaybillNumber      string
+	BillOfLadingNumber string
+	EquipmentID        string
+	EquipmentIDs       []string
+}
+
+// EquipmentStore reads equipment records, ordered by date added.
+type EquipmentStore interface {
+	ListEquipment(ctx context.Context, scope Scope, filter EquipmentFilter) ([]Equipment, error)
+	// EachEquipment calls fn with each record ListEquipment would return as
+	// it's read, stopping at the first error.
+	EachEquipment(ctx context.Context, scope Scope, filter EquipmentFilter, fn func(Equipment) error) error
+}
+
+// EventStore reads sightings. Sightings are scoped by the customer of the
+// record covering the car when it was sighted.
+type EventStore interface {
+	// ListEvents returns matching events ordered by sighting date.
+	ListEvents(ctx context.Context, scope Scope, filter EventFilter) ([]Event, error)
+	// EachEvent calls fn with each event ListEvents would return as it's
+	// read, stopping at the first error.
+	EachEvent(ctx context.Context, scope Scope, filter EventFilter, fn func(Event) error) error
+	// EventsSince returns up to limit matching events posted after the
+	// cursor, ordered by posting date and ID.
+	EventsSince(ctx context.Context, scope Scope, filter EventFilter, cursor EventCursor, limit int) ([]Event, error)
+	// LatestEventCursor returns the cursor of the last posted event of any
+	// customer, or the zero cursor when there are none.
+	LatestEventCursor(ctx context.Context) (EventCursor, error)
+}
+
+// LocationStore reads locations, ordered by ID.
+type LocationStore interface {
+	ListLocations(ctx context.Context) ([]Location, error)
+	EachLocation(ctx context.Context, fn func(Location) error) error
+	LocationsByID(ctx context.Context, ids []string) ([]Location, error)
+}
+
+// WaybillStore reads waybills, ordered by ID. Waybills are scoped by the
+// customer of their car's record in effect for the shipment, as EquipmentAt
+// picks it.
+type WaybillStore interface {
+	ListWaybills(ctx context.Context, scope Scope, filter WaybillFilter) ([]Waybill, error)
+	EachWaybill(ctx context.Context, scope Scope, filter WaybillFilter, fn func(Waybill) error) error
+	WaybillByID(ctx context.Context, scope Scope, id string) (Waybill, error)
+}
+
+// KeyStore looks up the API keys requests authenticate with.
+type KeyStore interface {
+	// ActiveAPIKey finds an unrevoked key by its hash.
+	ActiveAPIKey(ctx context.Context, hash string) (APIKey, error)
+	TouchAPIKey(ctx context.Context, id uint, at time.Time) error
+}
+
+// Revision identifies a state of ingested data. IDs increase with each
+// ingest run; the zero value means no run was recorded.
+type Revision struct {
+	ID         uint
+	ModifiedAt time.Time
+}
+
+// RevisionStore reports when ingested data last changed.
+type RevisionStore interface {
+	// Revision returns the latest ingest run of any of the datasets.
+	Revision(ctx context.Context, datasets ...string) (Revision, error)
+}
+
+// Stores bundles the stores the HTTP handlers read from.
+type Stores struct {
+	Waybills  WaybillStore
+	Events    EventStore
+	Equipment EquipmentStore
+	Locations LocationStore
+	Keys      KeyStore
+	Revisions RevisionStore
+	// Cache is the read cache the stores read through, if any, whose
+	// counters /metrics reports.
+	Cache *CachedStore
+}
@@ -0,0 +1,283 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/hashicorp/golang-lru/v2/expirable"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Cache stores encoded values by key, expiring them on its own. LRUCache
+// keeps them in process; a shared cache such as Redis can implement it for
+// several API instances to share.
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte) error
+}
+
+// LRUCache is an in-process Cache holding up to size entries, each for up to
+// ttl.
+type LRUCache struct {
+	lru *expirable.LRU[string, []byte]
+}
+
+func NewLRUCache(size int, ttl time.Duration) *LRUCache {
+	return &LRUCache{lru: expirable.NewLRU[string, []byte](size, nil, ttl)}
+}
+
+func (c *LRUCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	value, ok := c.lru.Get(key)
+	return value, ok, nil
+}
+
+func (c *LRUCache) Set(_ context.Context, key string, value []byte) error {
+	c.lru.Add(key, value)
+	return nil
+}
+
+// Reads CachedStore caches, named in its stats.
+const (
+	cachedWaybill   = "waybill"
+	cachedLocations = "locations"
+)
+
+// waybillDatasets are what a waybill read depends on: the waybill, and the
+// equipment its scope is checked against.
+var waybillDatasets = []string{DatasetEquipment, DatasetWaybills}
+
+// CacheStats counts the reads of one kind a CachedStore served. Errors are
+// reads or writes the cache failed, which fall back to the store.
+type CacheStats struct {
+	Hits   uint64
+	Misses uint64
+	Errors uint64
+}
+
+// CachedStore reads waybills by ID and locations through a Cache. Keys
+// include the revision of the datasets the value was read from, so an ingest
+// run makes earlier entries unreachable, in this process or any other sharing
+// the cache. Other reads go straight to the stores.
+//
+// Revisions are themselves reused for RevisionTTL rather than read on every
+// cached read, so an ingest run shows up within RevisionTTL. ETags use the
+// same revisions, so they never vouch for a newer revision than the cached
+// values they're sent with.
+type CachedStore struct {
+	stores Stores
+	cache  Cache
+	stats  map[string]*CacheStats
+
+	RevisionTTL time.Duration
+
+	mu        sync.Mutex
+	revisions map[string]cachedRevision
+}
+
+type cachedRevision struct {
+	rev  Revision
+	read time.Time
+}
+
+func NewCachedStore(stores Stores, cache Cache) *CachedStore {
+	return &CachedStore{
+		stores:      stores,
+		cache:       cache,
+		stats:       map[string]*CacheStats{cachedWaybill: {}, cachedLocations: {}},
+		RevisionTTL: time.Second,
+		revisions:   make(map[string]cachedRevision),
+	}
+}
+
+// Stores returns stores reading waybills, locations and revisions through the
+// cache.
+func (s *CachedStore) Stores() Stores {
+	stores := s.stores
+	stores.Waybills = s
+	stores.Locations = s
+	stores.Revisions = s
+	stores.Cache = s
+	return stores
+}
+
+// Stats returns the counts of each kind of cached read so far.
+func (s *CachedStore) Stats() map[string]CacheStats {
+	stats := make(map[string]CacheStats, len(s.stats))
+	for name, c := range s.stats {
+		stats[name] = CacheStats{
+			Hits:   atomic.LoadUint64(&c.Hits),
+			Misses: atomic.LoadUint64(&c.Misses),
+			Errors: atomic.LoadUint64(&c.Errors),
+		}
+	}
+	return stats
+}
+
+func (s *CachedStore) ListWaybills(ctx context.Context, scope Scope, filter WaybillFilter) ([]Waybill, error) {
+	return s.stores.Waybills.ListWaybills(ctx, scope, filter)
+}
+
+func (s *CachedStore) EachWaybill(ctx context.Context, scope Scope, filter WaybillFilter, fn func(Waybill) error) error {
+	return s.stores.Waybills.EachWaybill(ctx, scope, filter, fn)
+}
+
+func (s *CachedStore) WaybillByID(ctx context.Context, scope Scope, id string) (Waybill, error) {
+	key, err := s.key(ctx, cachedWaybill, waybillDatasets, scopeKey(scope), id)
+	if err != nil {
+		return Waybill{}, err
+	}
+	var waybill Waybill
+	err = s.through(ctx, cachedWaybill, key, &waybill, func() (err error) {
+		waybill, err = s.stores.Waybills.WaybillByID(ctx, scope, id)
+		return err
+	})
+	return waybill, err
+}
+
+func (s *CachedStore) ListLocations(ctx context.Context) ([]Location, error) {
+	key, err := s.key(ctx, cachedLocations, []string{DatasetLocations})
+	if err != nil {
+		return nil, err
+	}
+	var locations []Location
+	err = s.through(ctx, cachedLocations, key, &locations, func() (err error) {
+		locations, err = s.stores.Locations.ListLocations(ctx)
+		return err
+	})
+	return locations, err
+}
+
+func (s *CachedStore) EachLocation(ctx context.Context, fn func(Location) error) error {
+	return s.stores.Locations.EachLocation(ctx, fn)
+}
+
+// LocationsByID picks the locations out of the cached list, since there are
+// few and they're read together. The list is ordered by ID, so they are too.
+func (s *CachedStore) LocationsByID(ctx context.Context, ids []string) ([]Location, error) {
+	all, err := s.ListLocations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	locations := []Location{}
+	for _, l := range all {
+		if contains(ids, l.ID) {
+			locations = append(locations, l)
+		}
+	}
+	return locations, nil
+}
+
+// Revision returns the latest ingest run of any of the datasets, read from the
+// store at most once per RevisionTTL.
+func (s *CachedStore) Revision(ctx context.Context, datasets ...string) (Revision, error) {
+	key := strings.Join(datasets, ",")
+	now := time.Now()
+
+	s.mu.Lock()
+	cached, ok := s.revisions[key]
+	s.mu.Unlock()
+	if ok && now.Sub(cached.read) < s.RevisionTTL {
+		return cached.rev, nil
+	}
+
+	rev, err := s.stores.Revisions.Revision(ctx, datasets...)
+	if err != nil {
+		return Revision{}, err
+	}
+	s.mu.Lock()
+	s.revisions[key] = cachedRevision{rev: rev, read: now}
+	s.mu.Unlock()
+	return rev, nil
+}
+
+// key names a cached read by its kind, the revision of the datasets it
+// depends on and its arguments.
+func (s *CachedStore) key(ctx context.Context, name string, datasets []string, args ...string) (string, error) {
+	rev, err := s.Revision(ctx, datasets...)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(append([]string{name, fmt.Sprint(rev.ID)}, args...), ":"), nil
+}
+
+// through decodes the value cached under key into dest, or calls load to
+// fill dest and caches it. A failing cache only costs the lookup.
+func (s *CachedStore) through(ctx context.Context, name, key string, dest interface{}, load func() error) error {
+	stats := s.stats[name]
+
+	cached, ok, err := s.cache.Get(ctx, key)
+	if err == nil && ok {
+		err = json.Unmarshal(cached, dest)
+		if err == nil {
+			atomic.AddUint64(&stats.Hits, 1)
+			return nil
+		}
+	}
+	if err != nil {
+		atomic.AddUint64(&stats.Errors, 1)
+	}
+	atomic.AddUint64(&stats.Misses, 1)
+
+	if err := load(); err != nil {
+		return err
+	}
+	encoded, err := json.Marshal(dest)
+	if err == nil {
+		err = s.cache.Set(ctx, key, encoded)
+	}
+	if err != nil {
+		atomic.AddUint64(&stats.Errors, 1)
+	}
+	return nil
+}
+
+// scopeKey identifies the customers a scope sees, whatever order they're
+// listed in.
+func scopeKey(scope Scope) string {
+	if !scope.Restricted {
+		return "*"
+	}
+	customers := append([]string(nil), scope.Customers...)
+	sort.Strings(customers)
+	return strings.Join(customers, ",")
+}
+
+// metricsContentType is Prometheus's text exposition format.
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Metrics serves the read cache's counters in Prometheus's text format. There
+// are none when the cache is disabled.
+func (h *HTTP) Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var b strings.Builder
+		if h.cache != nil {
+			stats := h.cache.Stats()
+			names := make([]string, 0, len(stats))
+			for name := range stats {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			for _, m := range []struct {
+				name, help string
+				value      func(CacheStats) uint64
+			}{
+				{"telegraph_cache_hits_total", "Reads served from the read cache.", func(s CacheStats) uint64 { return s.Hits }},
+				{"telegraph_cache_misses_total", "Reads the read cache passed to the store.", func(s CacheStats) uint64 { return s.Misses }},
+				{"telegraph_cache_errors_total", "Failed read cache lookups and writes.", func(s CacheStats) uint64 { return s.Errors }},
+			} {
+				fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s counter\n", m.name, m.help, m.name)
+				for _, name := range names {
+					fmt.Fprintf(&b, "%s{cache=%q} %d\n", m.name, name, m.value(stats[name]))
+				}
+			}
+		}
+		c.Data(http.StatusOK, metricsContentType, []byte(b.String()))
+	}
+}
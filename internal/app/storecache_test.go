@@ -0,0 +1,160 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"github.com/coreyvan/backend-takehome/internal/app"
+	"github.com/coreyvan/backend-takehome/internal/ingest"
+	"go.uber.org/zap"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// brokenCache fails every lookup and write, like an unreachable Redis.
+type brokenCache struct{}
+
+func (brokenCache) Get(context.Context, string) ([]byte, bool, error) {
+	return nil, false, errors.New("connection refused")
+}
+
+func (brokenCache) Set(context.Context, string, []byte) error {
+	return errors.New("connection refused")
+}
+
+func TestCachedStore(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	store := app.NewCachedStore(app.NewGormStore(db).Stores(), app.NewLRUCache(100, time.Minute))
+	store.RevisionTTL = 0
+	stores := store.Stores()
+
+	checkStats := func(name string, want app.CacheStats) {
+		t.Helper()
+		if got := store.Stats()[name]; got != want {
+			t.Errorf("%s stats = %+v, want %+v", name, got, want)
+		}
+	}
+
+	for k := 0; k < 2; k++ {
+		waybill, err := stores.Waybills.WaybillByID(ctx, app.Scope{}, "7")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if waybill.ID != "7" || waybill.Routes == "" {
+			t.Fatalf("waybill = %+v, want waybill 7 with its route", waybill)
+		}
+	}
+	checkStats("waybill", app.CacheStats{Hits: 1, Misses: 1})
+
+	// Scopes are cached apart, so a customer can't read another's cached
+	// waybill.
+	if _, err := stores.Waybills.WaybillByID(ctx, app.Scope{Customers: []string{"OTHERCO"}, Restricted: true}, "7"); !errors.Is(err, app.ErrNotFound) {
+		t.Errorf("other customer's waybill: err = %v, want ErrNotFound", err)
+	}
+	checkStats("waybill", app.CacheStats{Hits: 1, Misses: 2})
+
+	// Reloading waybills invalidates them; reloading locations doesn't.
+	i := ingest.NewIngester(db, zap.NewNop())
+	data := testData(t)
+	if _, err := i.ProcessLocations(filepath.Join(data, "locations.csv")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stores.Waybills.WaybillByID(ctx, app.Scope{}, "7"); err != nil {
+		t.Fatal(err)
+	}
+	checkStats("waybill", app.CacheStats{Hits: 2, Misses: 2})
+	if _, err := i.ProcessWaybills(filepath.Join(data, "waybills.csv")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stores.Waybills.WaybillByID(ctx, app.Scope{}, "7"); err != nil {
+		t.Fatal(err)
+	}
+	checkStats("waybill", app.CacheStats{Hits: 2, Misses: 3})
+
+	// Locations by ID are picked from the cached list.
+	all, err := stores.Locations.ListLocations(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	some, err := stores.Locations.LocationsByID(ctx, []string{all[1].ID, all[0].ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(some) != 2 {
+		t.Errorf("LocationsByID = %v, want 2 locations", some)
+	}
+	checkStats("locations", app.CacheStats{Hits: 1, Misses: 1})
+}
+
+// countedRevisions counts the revisions read from the store.
+type countedRevisions struct {
+	app.RevisionStore
+	reads int
+}
+
+func (r *countedRevisions) Revision(ctx context.Context, datasets ...string) (app.Revision, error) {
+	r.reads++
+	return r.RevisionStore.Revision(ctx, datasets...)
+}
+
+func TestCachedStoreReusesRevisions(t *testing.T) {
+	ctx := context.Background()
+	stores := app.NewGormStore(newTestDB(t)).Stores()
+	revisions := &countedRevisions{RevisionStore: stores.Revisions}
+	stores.Revisions = revisions
+	store := app.NewCachedStore(stores, app.NewLRUCache(100, time.Minute))
+	store.RevisionTTL = 50 * time.Millisecond
+
+	read := func() {
+		t.Helper()
+		for k := 0; k < 3; k++ {
+			if _, err := store.Stores().Waybills.WaybillByID(ctx, app.Scope{}, "7"); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	read()
+	if revisions.reads != 1 {
+		t.Errorf("3 waybill reads read the revision %d times, want once", revisions.reads)
+	}
+	time.Sleep(100 * time.Millisecond)
+	read()
+	if revisions.reads != 2 {
+		t.Errorf("reads after the revision ttl read it %d times in all, want twice", revisions.reads)
+	}
+}
+
+func TestCachedStoreFallsBack(t *testing.T) {
+	store := app.NewCachedStore(app.NewGormStore(newTestDB(t)).Stores(), brokenCache{})
+
+	waybill, err := store.Stores().Waybills.WaybillByID(context.Background(), app.Scope{}, "7")
+	if err != nil || waybill.ID != "7" {
+		t.Fatalf("WaybillByID = %v, %v; want waybill 7 from the store", waybill.ID, err)
+	}
+	if got, want := store.Stats()["waybill"], (app.CacheStats{Misses: 1, Errors: 2}); got != want {
+		t.Errorf("stats = %+v, want %+v", got, want)
+	}
+}
+
+func TestLRUCacheExpires(t *testing.T) {
+	ctx := context.Background()
+	cache := app.NewLRUCache(2, 50*time.Millisecond)
+
+	for _, key := range []string{"a", "b", "c"} {
+		if err := cache.Set(ctx, key, []byte(key)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, ok, _ := cache.Get(ctx, "a"); ok {
+		t.Error("least recently used entry wasn't evicted")
+	}
+	if v, ok, _ := cache.Get(ctx, "c"); !ok || string(v) != "c" {
+		t.Errorf("Get(c) = %q, %v", v, ok)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if _, ok, _ := cache.Get(ctx, "c"); ok {
+		t.Error("entry outlived its ttl")
+	}
+}
@@ -0,0 +1,202 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	streamPollInterval = 2 * time.Second
+	streamBatchSize    = 500
+)
+
+// EventCursor is the position of the last event sent on a stream. It is sent
+// as the SSE id and read back from Last-Event-ID so a reconnecting client
+// resumes without gaps or duplicates. Events are ordered by posting date and
+// then ID, since several sightings are often posted in the same second. A
+// cursor without an ID, as an after param gives, is past every event posted
+// at its date.
+type EventCursor struct {
+	PostingDate time.Time
+	ID          string
+}
+
+func (s EventCursor) String() string {
+	return fmt.Sprintf("%s/%s", s.PostingDate.UTC().Format(time.RFC3339Nano), s.ID)
+}
+
+func parseEventCursor(str string) (EventCursor, error) {
+	date, id, ok := strings.Cut(str, "/")
+	if !ok {
+		return EventCursor{}, fmt.Errorf("expected <posting_date>/<id>, got %q", str)
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, date)
+	if err != nil {
+		return EventCursor{}, fmt.Errorf("parsing posting date: %w", err)
+	}
+
+	return EventCursor{PostingDate: t, ID: id}, nil
+}
+
+func (h *HTTP) EventStream() gin.HandlerFunc {
+	return h.streamEvents(func(c *gin.Context) EventFilter {
+		return EventFilter{}
+	})
+}
+
+func (h *HTTP) WaybillEventStream() gin.HandlerFunc {
+	stream := h.streamEvents(func(c *gin.Context) EventFilter {
+		return EventFilter{WaybillID: c.Param("id")}
+	})
+
+	return func(c *gin.Context) {
+		if _, ok := h.findWaybill(c); !ok {
+			return
+		}
+
+		stream(c)
+	}
+}
+
+// errStreamDraining ends event streams when the server starts shutting down.
+var errStreamDraining = errors.New("the server is shutting down")
+
+// eventPoller reads the events posted after a stream's cursor, polling the
+// store for new rows. The HTTP and gRPC event streams both send its batches.
+type eventPoller struct {
+	events   EventStore
+	draining <-chan struct{}
+	scope    Scope
+	filter   EventFilter
+	cursor   EventCursor
+	ticker   *time.Ticker
+	polled   bool
+}
+
+// newEventPoller starts polling after cursor, or after the latest posted
+// event for the zero cursor, so events ingested later are sent whatever their
+// posting date. Stop the poller when the stream ends.
+func (h *HTTP) newEventPoller(ctx context.Context, scope Scope, filter EventFilter, cursor EventCursor) (*eventPoller, error) {
+	if cursor.PostingDate.IsZero() {
+		latest, err := h.events.LatestEventCursor(ctx)
+		if err != nil {
+			return nil, err
+		}
+		cursor = latest
+	}
+
+	return &eventPoller{
+		events:   h.events,
+		draining: h.draining,
+		scope:    scope,
+		filter:   filter,
+		cursor:   cursor,
+		ticker:   time.NewTicker(streamPollInterval),
+	}, nil
+}
+
+func (p *eventPoller) Stop() {
+	p.ticker.Stop()
+}
+
+// next waits for the next poll, except the first, and returns the events
+// posted since the last batch. It returns ctx.Err() once ctx is done and
+// errStreamDraining once the server starts shutting down.
+func (p *eventPoller) next(ctx context.Context) ([]Event, error) {
+	if p.polled {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-p.draining:
+			return nil, errStreamDraining
+		case <-p.ticker.C:
+		}
+	}
+	p.polled = true
+
+	events, err := p.events.EventsSince(ctx, p.scope, p.filter, p.cursor, streamBatchSize)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("polling events for stream: %w", err)
+	}
+	if len(events) > 0 {
+		last := events[len(events)-1]
+		p.cursor = EventCursor{PostingDate: last.PostingDate, ID: last.ID}
+	}
+	return events, nil
+}
+
+// streamEvents pushes events posted after the client's cursor as server-sent
+// events. Without a Last-Event-ID header or an after query param the stream
+// starts after the latest posted event.
+func (h *HTTP) streamEvents(filter func(c *gin.Context) EventFilter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var cursor EventCursor
+		if last := c.GetHeader("Last-Event-ID"); last != "" {
+			parsed, err := parseEventCursor(last)
+			if err != nil {
+				h.invalid(c, FieldError{Name: "Last-Event-ID", In: "header", Reason: "must be an event id sent on this stream"})
+				return
+			}
+			cursor = parsed
+		} else {
+			var errs []FieldError
+			after := queryTime(c, "after", &errs)
+			if len(errs) > 0 {
+				h.invalid(c, errs...)
+				return
+			}
+			cursor = EventCursor{PostingDate: after}
+		}
+
+		ctx := c.Request.Context()
+		poller, err := h.newEventPoller(ctx, customerScope(c), filter(c), cursor)
+		if err != nil {
+			h.internalError(c, err)
+			return
+		}
+		defer poller.Stop()
+
+		c.Header("Content-Type", sse.ContentType)
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Header("X-Accel-Buffering", "no")
+
+		c.Stream(func(w io.Writer) bool {
+			events, err := poller.next(ctx)
+			switch {
+			case ctx.Err() != nil:
+				return false
+			case errors.Is(err, errStreamDraining):
+				c.Render(-1, sse.Event{Event: "error", Data: h.newProblem(c, http.StatusServiceUnavailable, CodeShuttingDown, "The server is shutting down. Reconnect to resume the stream.")})
+				return false
+			case err != nil:
+				h.log.Sugar().Errorw(err.Error(), "request_id", c.GetString(requestIDKey), "path", c.Request.URL.Path)
+				c.Render(-1, sse.Event{Event: "error", Data: h.newProblem(c, http.StatusInternalServerError, CodeInternal, "The server could not read new events.")})
+				return false
+			}
+
+			if len(events) == 0 {
+				// Comment lines keep proxies from timing out idle streams.
+				_, _ = io.WriteString(w, ": keepalive\n\n")
+				return true
+			}
+
+			for _, e := range events {
+				cursor := EventCursor{PostingDate: e.PostingDate, ID: e.ID}
+				c.Render(-1, sse.Event{Id: cursor.String(), Event: "event", Data: e})
+			}
+			return true
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package app_test
+
+import (
+	"bufio"
+	"context"
+	"github.com/coreyvan/backend-takehome/internal/app"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// openStream starts reading an event stream, which has read its cursor once
+// the response arrives.
+func openStream(t *testing.T, ctx context.Context, url, key string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("X-API-Key", key)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { res.Body.Close() })
+	return res
+}
+
+// streamIDs reads the ids of the events sent on a stream until it has want
+// of them or the stream ends.
+func streamIDs(res *http.Response, want int) []string {
+	var ids []string
+	lines := bufio.NewScanner(res.Body)
+	for len(ids) < want && lines.Scan() {
+		if id, ok := strings.CutPrefix(lines.Text(), "id:"); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// TestEventStreamStartsAtLatestEvent checks a stream without a cursor sends
+// events ingested after it started, though they were posted long before.
+func TestEventStreamStartsAtLatestEvent(t *testing.T) {
+	db := newTestDB(t)
+	srv, keys, _ := serveTestAPI(t, db)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream := openStream(t, ctx, srv.URL+"/events/stream", keys["admin"])
+
+	late := app.Event{
+		ID:           "99999",
+		EquipmentID:  "PMRX346210",
+		SightingDate: time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC),
+		PostingDate:  time.Date(2021, 12, 1, 1, 0, 0, 0, time.UTC),
+		WaybillID:    "7",
+	}
+	if err := db.Create(&late).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if got := streamIDs(stream, 1); len(got) != 1 || !strings.HasSuffix(got[0], "/99999") {
+		t.Errorf("stream sent %v, want the late event", got)
+	}
+}
+
+// TestEventStreamAfterIsExclusive checks after= skips events posted at that
+// time, as resuming from a full cursor does.
+func TestEventStreamAfterIsExclusive(t *testing.T) {
+	srv, keys, _ := newTestAPI(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	// Event 43128 was posted at 2021-08-20T03:17:19Z.
+	got := streamIDs(openStream(t, ctx, srv.URL+"/waybills/7/events/stream?after=2021-08-20T03:17:19Z", keys["admin"]), 1)
+	if len(got) != 1 || strings.HasSuffix(got[0], "/43128") {
+		t.Errorf("stream after the posting date of 43128 started with %v", got)
+	}
+}
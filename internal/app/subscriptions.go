@@ -0,0 +1,313 @@
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// targetLookupTimeout bounds resolving a subscription's target host.
+const targetLookupTimeout = 2 * time.Second
+
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+// Subscription asks for matching events to be POSTed to TargetURL. Empty
+// filters match everything; EventCodes is stored comma-separated.
+type Subscription struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	TargetURL   string    `json:"target_url"`
+	Secret      string    `json:"secret,omitempty"`
+	WaybillID   string    `json:"waybill_id,omitempty"`
+	EquipmentID string    `json:"equipment_id,omitempty"`
+	EventCodes  string    `json:"event_codes,omitempty"`
+	Customer    string    `json:"customer,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Delivery is one event queued for one subscription. The pair is unique so
+// re-ingesting the same events doesn't notify subscribers twice.
+type Delivery struct {
+	ID             uint              `gorm:"primaryKey" json:"id"`
+	SubscriptionID uint              `json:"subscription_id"`
+	EventID        string            `json:"event_id"`
+	Payload        string            `json:"payload"`
+	Status         string            `json:"status"`
+	Attempts       int               `json:"attempts"`
+	NextAttemptAt  time.Time         `json:"next_attempt_at"`
+	LastError      string            `json:"last_error,omitempty"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+	Log            []DeliveryAttempt `gorm:"foreignKey:DeliveryID" json:"log,omitempty"`
+}
+
+// DeliveryAttempt logs a single POST of a delivery.
+type DeliveryAttempt struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	DeliveryID  uint      `json:"delivery_id"`
+	Attempt     int       `json:"attempt"`
+	StatusCode  int       `json:"status_code,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	DurationMS  int64     `json:"duration_ms"`
+	AttemptedAt time.Time `json:"attempted_at"`
+}
+
+// DeadLetter holds a delivery that exhausted its retries.
+type DeadLetter struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	DeliveryID     uint      `json:"delivery_id"`
+	SubscriptionID uint      `json:"subscription_id"`
+	EventID        string    `json:"event_id"`
+	Payload        string    `json:"payload"`
+	LastError      string    `json:"last_error"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type subscriptionRequest struct {
+	TargetURL   string   `json:"target_url"`
+	WaybillID   string   `json:"waybill_id"`
+	EquipmentID string   `json:"equipment_id"`
+	EventCodes  []string `json:"event_codes"`
+	Customer    string   `json:"customer"`
+}
+
+// Codes returns the event codes the subscription is filtered on.
+func (s Subscription) Codes() []string {
+	if s.EventCodes == "" {
+		return nil
+	}
+	return strings.Split(s.EventCodes, ",")
+}
+
+// Matches reports whether an event passes the subscription's filters. The
+// customer owning the event's car is resolved by the caller, since it depends
+// on the equipment record in effect at the sighting.
+func (s Subscription) Matches(e Event, customer string) bool {
+	if s.WaybillID != "" && s.WaybillID != e.WaybillID {
+		return false
+	}
+	if s.EquipmentID != "" && s.EquipmentID != e.EquipmentID {
+		return false
+	}
+	if s.Customer != "" && s.Customer != customer {
+		return false
+	}
+	if codes := s.Codes(); len(codes) > 0 {
+		for _, code := range codes {
+			if code == e.SightingEventCode {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func (h *HTTP) CreateSubscription() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req subscriptionRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.problem(c, http.StatusBadRequest, CodeMalformedBody, fmt.Sprintf("The body must be a JSON subscription: %v.", err))
+			return
+		}
+
+		var errs []FieldError
+		target, err := url.Parse(req.TargetURL)
+		if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+			errs = append(errs, FieldError{Name: "target_url", In: "body", Reason: "must be an absolute http(s) URL"})
+		} else if !h.allowPrivateTargets {
+			// Names that don't resolve yet are let through; deliveries check
+			// the address they connect to as well.
+			ctx, cancel := context.WithTimeout(c.Request.Context(), targetLookupTimeout)
+			err := checkTargetHost(ctx, target.Hostname())
+			cancel()
+			if errors.Is(err, ErrPrivateTarget) {
+				errs = append(errs, FieldError{Name: "target_url", In: "body", Reason: "must resolve to a public address"})
+			}
+		}
+		for k, code := range req.EventCodes {
+			if code == "" || strings.Contains(code, ",") {
+				errs = append(errs, FieldError{Name: fmt.Sprintf("event_codes[%d]", k), In: "body", Reason: "must be a non-empty event code without commas"})
+			}
+		}
+
+		// Keys bound to customers may only subscribe to their own cars.
+		scope := customerScope(c)
+		if scope.Restricted && req.Customer == "" {
+			if len(scope.Customers) == 1 {
+				req.Customer = scope.Customers[0]
+			} else {
+				errs = append(errs, FieldError{Name: "customer", In: "body", Reason: "is required for keys bound to several customers"})
+			}
+		}
+
+		if len(errs) > 0 {
+			h.invalid(c, errs...)
+			return
+		}
+		if !scope.Allows(req.Customer) {
+			h.problem(c, http.StatusForbidden, CodeCustomerForbidden, fmt.Sprintf("This API key can't subscribe to customer %s.", req.Customer))
+			return
+		}
+
+		secret, err := newSecret()
+		if err != nil {
+			h.internalError(c, fmt.Errorf("generating subscription secret: %w", err))
+			return
+		}
+
+		sub := Subscription{
+			TargetURL:   req.TargetURL,
+			Secret:      secret,
+			WaybillID:   req.WaybillID,
+			EquipmentID: req.EquipmentID,
+			EventCodes:  strings.Join(req.EventCodes, ","),
+			Customer:    req.Customer,
+		}
+		if err := h.db.Create(&sub).Error; err != nil {
+			h.internalError(c, fmt.Errorf("creating subscription: %w", err))
+			return
+		}
+
+		// The secret is only ever returned here; receivers need it to verify
+		// the signature on each delivery.
+		c.JSON(http.StatusCreated, sub)
+	}
+}
+
+func (h *HTTP) Subscriptions() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var subs []Subscription
+		result := h.db.Scopes(h.scopeSubscriptions(c)).Omit("secret").Order("id").Find(&subs)
+		if result.Error != nil {
+			h.internalError(c, fmt.Errorf("finding all subscriptions: %w", result.Error))
+			return
+		}
+		c.JSON(http.StatusOK, subs)
+	}
+}
+
+func (h *HTTP) SubscriptionByID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sub, ok := h.findSubscription(c)
+		if !ok {
+			return
+		}
+		c.JSON(http.StatusOK, sub)
+	}
+}
+
+func (h *HTTP) DeleteSubscription() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sub, ok := h.findSubscription(c)
+		if !ok {
+			return
+		}
+
+		err := h.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("subscription_id = ? AND status = ?", sub.ID, DeliveryPending).Delete(&Delivery{}).Error; err != nil {
+				return fmt.Errorf("deleting pending deliveries: %w", err)
+			}
+			return tx.Delete(&sub).Error
+		})
+		if err != nil {
+			h.internalError(c, fmt.Errorf("deleting subscription: %w", err))
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func (h *HTTP) SubscriptionDeliveries() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sub, ok := h.findSubscription(c)
+		if !ok {
+			return
+		}
+
+		where := h.db.Where("subscription_id = ?", sub.ID)
+		if status := c.Query("status"); status != "" {
+			where = where.Where("status = ?", status)
+		}
+
+		var deliveries []Delivery
+		result := where.Preload("Log", func(db *gorm.DB) *gorm.DB {
+			return db.Order("attempt")
+		}).Order("id").Find(&deliveries)
+		if result.Error != nil {
+			h.internalError(c, fmt.Errorf("finding subscription deliveries: %w", result.Error))
+			return
+		}
+		c.JSON(http.StatusOK, deliveries)
+	}
+}
+
+func (h *HTTP) SubscriptionDeadLetters() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sub, ok := h.findSubscription(c)
+		if !ok {
+			return
+		}
+
+		var dead []DeadLetter
+		result := h.db.Where("subscription_id = ?", sub.ID).Order("id").Find(&dead)
+		if result.Error != nil {
+			h.internalError(c, fmt.Errorf("finding subscription dead letters: %w", result.Error))
+			return
+		}
+		c.JSON(http.StatusOK, dead)
+	}
+}
+
+func (h *HTTP) findSubscription(c *gin.Context) (Subscription, bool) {
+	var errs []FieldError
+	id := pathID(c, &errs)
+	if len(errs) > 0 {
+		h.invalid(c, errs...)
+		return Subscription{}, false
+	}
+
+	var sub Subscription
+	result := h.db.Scopes(h.scopeSubscriptions(c)).Omit("secret").Where("id = ?", id).First(&sub)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			h.problem(c, http.StatusNotFound, CodeSubscriptionNotFound, fmt.Sprintf("Subscription %d was not found.", id))
+			return Subscription{}, false
+		}
+		h.internalError(c, fmt.Errorf("finding subscription by id: %w", result.Error))
+		return Subscription{}, false
+	}
+
+	return sub, true
+}
+
+// scopeSubscriptions limits subscriptions to the request's customers.
+func (h *HTTP) scopeSubscriptions(c *gin.Context) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		scope := customerScope(c)
+		if !scope.Restricted {
+			return db
+		}
+		return db.Where("customer IN ?", scope.Customers)
+	}
+}
+
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,148 @@
+package app
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// The fields a tracking reference is matched against, in the order they're
+// tried.
+const (
+	MatchedOnWaybillNumber      = "waybill_number"
+	MatchedOnBillOfLadingNumber = "bill_of_lading_number"
+	MatchedOnEquipmentID        = "equipment_id"
+)
+
+// TrackMatch is a waybill matching a tracking reference.
+type TrackMatch struct {
+	ID                 string    `json:"id"`
+	WaybillNumber      string    `json:"waybill_number"`
+	BillOfLadingNumber string    `json:"bill_of_lading_number"`
+	EquipmentID        string    `json:"equipment_id"`
+	WaybillDate        time.Time `json:"waybill_date"`
+	Href               string    `json:"href"`
+}
+
+// Tracking resolves a reference customers quote to the shipment it means.
+// Waybill numbers and bills of lading are reused and cars carry many
+// shipments, so Matches lists every waybill matching the reference, newest
+// first. Waybill is the one selected, if any.
+type Tracking struct {
+	Reference   string         `json:"reference"`
+	MatchedOn   string         `json:"matched_on"`
+	Waybill     *WaybillDetail `json:"waybill,omitempty"`
+	LatestEvent *Event         `json:"latest_event,omitempty"`
+	Matches     []TrackMatch   `json:"matches"`
+}
+
+// LatestWaybills returns the waybills a reference most likely means at asOf:
+// those with the latest waybill date on or before it. Several are returned
+// when they share that date.
+func LatestWaybills(waybills []Waybill, asOf time.Time) []Waybill {
+	var latest []Waybill
+	for _, w := range waybills {
+		if w.WaybillDate.After(asOf) {
+			continue
+		}
+		switch {
+		case len(latest) == 0 || w.WaybillDate.After(latest[0].WaybillDate):
+			latest = []Waybill{w}
+		case w.WaybillDate.Equal(latest[0].WaybillDate):
+			latest = append(latest, w)
+		}
+	}
+	return latest
+}
+
+// Track looks a reference up as a waybill number, then a bill of lading
+// number, then an equipment ID, and selects the latest matching waybill dated
+// on or before as_of (now by default). It responds 300 Multiple Choices when
+// several waybills share that date.
+func (h *HTTP) Track() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var errs []FieldError
+		asOf := queryTime(c, "as_of", &errs)
+		if len(errs) > 0 {
+			h.invalid(c, errs...)
+			return
+		}
+		if asOf.IsZero() {
+			asOf = time.Now().UTC()
+		}
+
+		reference := c.Param("reference")
+		res := Tracking{Reference: reference, Matches: []TrackMatch{}}
+
+		var matches []Waybill
+		for _, try := range []struct {
+			field  string
+			filter WaybillFilter
+		}{
+			{MatchedOnWaybillNumber, WaybillFilter{WaybillNumber: reference}},
+			{MatchedOnBillOfLadingNumber, WaybillFilter{BillOfLadingNumber: reference}},
+			{MatchedOnEquipmentID, WaybillFilter{EquipmentID: reference}},
+		} {
+			found, err := h.waybills.ListWaybills(c.Request.Context(), customerScope(c), try.filter)
+			if err != nil {
+				h.internalError(c, fmt.Errorf("finding waybills by %s: %w", try.field, err))
+				return
+			}
+			if len(found) > 0 {
+				res.MatchedOn, matches = try.field, found
+				break
+			}
+		}
+		if len(matches) == 0 {
+			h.problem(c, http.StatusNotFound, CodeReferenceNotFound, fmt.Sprintf("No waybill matches %s.", reference))
+			return
+		}
+
+		sort.SliceStable(matches, func(i, j int) bool {
+			if !matches[i].WaybillDate.Equal(matches[j].WaybillDate) {
+				return matches[i].WaybillDate.After(matches[j].WaybillDate)
+			}
+			return matches[i].ID < matches[j].ID
+		})
+		for _, w := range matches {
+			res.Matches = append(res.Matches, TrackMatch{
+				ID:                 w.ID,
+				WaybillNumber:      w.WaybillNumber,
+				BillOfLadingNumber: w.BillOfLadingNumber,
+				EquipmentID:        w.EquipmentID,
+				WaybillDate:        w.WaybillDate,
+				Href:               requestPrefix(c) + "/waybills/" + w.ID,
+			})
+		}
+
+		latest := LatestWaybills(matches, asOf)
+		switch len(latest) {
+		case 0:
+			h.problem(c, http.StatusNotFound, CodeReferenceNotFound, fmt.Sprintf("No waybill matching %s is dated on or before %s.", reference, asOf.Format(time.RFC3339)))
+			return
+		case 1:
+		default:
+			c.JSON(http.StatusMultipleChoices, res)
+			return
+		}
+
+		details, err := h.waybillDetails(c.Request.Context(), customerScope(c), latest, nil, requestVersion(c))
+		if err != nil {
+			h.internalError(c, err)
+			return
+		}
+		res.Waybill = &details[0]
+
+		events, err := h.events.ListEvents(c.Request.Context(), Scope{}, EventFilter{WaybillID: res.Waybill.ID})
+		if err != nil {
+			h.internalError(c, fmt.Errorf("finding waybill events: %w", err))
+			return
+		}
+		if len(events) > 0 {
+			res.LatestEvent = &events[len(events)-1]
+		}
+		c.JSON(http.StatusOK, res)
+	}
+}
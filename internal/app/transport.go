@@ -1,113 +1,266 @@
 package app
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
+	"github.com/coreyvan/backend-takehome/internal/config"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 	"net/http"
-	"time"
+	"sync"
 )
 
 type HTTP struct {
-	port string
-	log  *zap.Logger
-	db   *gorm.DB
-	g    *gin.Engine
+	cfg        config.HTTP
+	log        *zap.Logger
+	g          *gin.Engine
+	routesOnce sync.Once
+	demurrage  DemurrageConfig
+	// allowPrivateTargets lets subscriptions POST to non-public addresses.
+	allowPrivateTargets bool
+
+	waybills  WaybillStore
+	events    EventStore
+	equipment EquipmentStore
+	locations LocationStore
+	keys      KeyStore
+	revisions RevisionStore
+	// cache is the read cache in front of the stores, if there is one.
+	cache *CachedStore
+
+	serverOnce sync.Once
+	server     *http.Server
+	// draining is closed when the server starts shutting down, ending event
+	// streams and failing /readyz.
+	draining  chan struct{}
+	drainOnce sync.Once
+
+	// db backs subscriptions and alerts, which are only served when it's set.
+	db *gorm.DB
 }
 
-func NewHTTP(log *zap.Logger, port string, db *gorm.DB) *HTTP {
-	if db == nil {
-		panic("db was nil")
+func NewHTTP(log *zap.Logger, cfg config.HTTP, stores Stores, db *gorm.DB) *HTTP {
+	if stores.Waybills == nil || stores.Events == nil || stores.Equipment == nil || stores.Locations == nil || stores.Keys == nil || stores.Revisions == nil {
+		panic("stores were incomplete")
 	}
 
 	gin.SetMode(gin.ReleaseMode)
 
 	return &HTTP{
-		port: port,
-		log:  log,
-		g:    gin.Default(),
-		db:   db,
+		cfg:       cfg,
+		log:       log,
+		g:         gin.New(),
+		demurrage: DefaultDemurrageConfig,
+		waybills:  stores.Waybills,
+		events:    stores.Events,
+		equipment: stores.Equipment,
+		locations: stores.Locations,
+		keys:      stores.Keys,
+		revisions: stores.Revisions,
+		cache:     stores.Cache,
+		db:        db,
+		draining:  make(chan struct{}),
 	}
 }
 
+// Listen serves the API on the configured port until Shutdown, when it
+// returns http.ErrServerClosed.
 func (h *HTTP) Listen() error {
-	h.routes()
+	return h.httpServer().ListenAndServe()
+}
 
-	if err := h.migrate(); err != nil {
-		return fmt.Errorf("migrating models: %w", err)
-	}
+// Shutdown stops accepting connections and waits for requests in flight to
+// finish until ctx is done. Event streams end as soon as it's called, since
+// they'd otherwise never finish.
+func (h *HTTP) Shutdown(ctx context.Context) error {
+	h.drainOnce.Do(func() { close(h.draining) })
+	return h.httpServer().Shutdown(ctx)
+}
 
-	return h.g.Run(fmt.Sprintf(":%s", h.port))
+// httpServer returns the server Listen runs, created on first use so
+// Shutdown can't miss it.
+func (h *HTTP) httpServer() *http.Server {
+	h.serverOnce.Do(func() {
+		h.server = &http.Server{
+			Addr:         fmt.Sprintf(":%s", h.cfg.Port),
+			Handler:      h.Handler(),
+			ReadTimeout:  h.cfg.ReadTimeout,
+			WriteTimeout: h.cfg.WriteTimeout,
+			IdleTimeout:  h.cfg.IdleTimeout,
+		}
+	})
+	return h.server
 }
 
-func (h *HTTP) routes() {
-	h.g.GET("/equipment", h.Equipment())
-	h.g.GET("/events", h.Events())
-	h.g.GET("/locations", h.Locations())
-	h.g.GET("/waybills", h.Waybills())
-	h.g.GET("/waybills/:id", h.WaybillsByID())
-	h.g.GET("/waybills/:id/equipment", h.WaybillEquipment())
-	h.g.GET("/waybills/:id/events", h.WaybillEvents())
-	h.g.GET("/waybills/:id/locations", h.WaybillLocations())
-	h.g.GET("/waybills/:id/route", h.WaybillRoute())
-	h.g.GET("/waybills/:id/parties", h.WaybillParties())
+// Handler returns the API's handler, registering the routes on first use.
+func (h *HTTP) Handler() http.Handler {
+	h.routesOnce.Do(h.routes)
+	return h.g
 }
 
-func (h *HTTP) migrate() error {
-	if err := h.db.AutoMigrate(&Location{}); err != nil {
-		return fmt.Errorf("migrating locations: %w", err)
-	}
+// Routes lists the registered routes.
+func (h *HTTP) Routes() gin.RoutesInfo {
+	h.routesOnce.Do(h.routes)
+	return h.g.Routes()
+}
 
-	if err := h.db.AutoMigrate(&Event{}); err != nil {
-		return fmt.Errorf("migrating locations: %w", err)
+func (h *HTTP) routes() {
+	h.g.Use(gin.Logger(), requestID, gin.CustomRecovery(h.recovered))
+	// Unknown paths need an API key too, so routes can't be probed without one.
+	h.g.NoRoute(h.authenticate(), h.routeNotFound)
+
+	// The docs describe every version, and like the operational routes don't
+	// need an API key.
+	h.g.GET("/openapi.json", h.OpenAPISpec())
+	h.g.GET("/docs", h.Docs())
+	h.g.GET("/metrics", h.Metrics())
+	h.g.GET("/healthz", h.Healthz())
+	h.g.GET("/readyz", h.Readyz())
+
+	for _, g := range apiGroups {
+		h.api(h.g.Group(g.Prefix, h.versioned(g), h.authenticate()))
 	}
 
-	if err := h.db.AutoMigrate(&Waybill{}); err != nil {
-		return fmt.Errorf("migrating locations: %w", err)
-	}
+	// The GraphQL schema evolves in place, deprecating fields rather than
+	// versioning the route.
+	h.g.POST("/graphql", h.authenticate(), h.GraphQL())
+}
 
-	if err := h.db.AutoMigrate(&Equipment{}); err != nil {
-		return fmt.Errorf("migrating locations: %w", err)
+// api registers the API's routes on r, once per apiGroup. Handlers serve each
+// version's representation according to requestVersion.
+func (h *HTTP) api(r gin.IRoutes) {
+	r.GET("/equipment", h.cached(cacheEquipment), h.Equipment())
+	r.GET("/equipment/:id/distance", h.cached(cacheWaybills), h.EquipmentDistance())
+	r.GET("/events", h.cached(cacheEvents), h.Events())
+	r.GET("/events/stream", h.EventStream())
+	r.GET("/locations", h.cached(cacheLocations), h.Locations())
+	r.GET("/locations.geojson", h.cached(cacheLocations), asGeoJSON, h.Locations())
+	r.GET("/waybills", h.cached(cacheWaybills), h.Waybills())
+	r.GET("/waybills/:id", h.cached(cacheWaybills), h.WaybillsByID())
+	r.GET("/waybills/:id/equipment", h.cached(cacheWaybills), h.WaybillEquipment())
+	r.GET("/waybills/:id/events", h.cached(cacheWaybills), h.WaybillEvents())
+	r.GET("/waybills/:id/events/stream", h.WaybillEventStream())
+	r.GET("/waybills/:id/locations", h.cached(cacheWaybills), h.WaybillLocations())
+	r.GET("/waybills/:id/route", h.cached(cacheWaybills), h.WaybillRoute())
+	r.GET("/waybills/:id/parties", h.cached(cacheWaybills), h.WaybillParties())
+	r.GET("/waybills/:id/distance", h.cached(cacheWaybills), h.WaybillDistance())
+	r.GET("/waybills/:id/track", h.cached(cacheWaybills), h.WaybillTrack())
+	r.GET("/waybills/:id/track.geojson", h.cached(cacheWaybills), h.WaybillTrack())
+	r.GET("/waybills/:id/demurrage", h.cached(cacheNow), h.WaybillDemurrage())
+	r.GET("/reports/demurrage", h.cached(cacheNow), h.DemurrageReport())
+	r.GET("/track/:reference", h.cached(cacheNow), h.Track())
+
+	if h.db == nil {
+		return
 	}
-
-	return nil
+	r.POST("/subscriptions", h.CreateSubscription())
+	r.GET("/subscriptions", h.cached(cacheNone), h.Subscriptions())
+	r.GET("/subscriptions/:id", h.cached(cacheNone), h.SubscriptionByID())
+	r.DELETE("/subscriptions/:id", h.DeleteSubscription())
+	r.GET("/subscriptions/:id/deliveries", h.cached(cacheNone), h.SubscriptionDeliveries())
+	r.GET("/subscriptions/:id/dead-letters", h.cached(cacheNone), h.SubscriptionDeadLetters())
+	r.GET("/alerts", h.cached(cacheNone), h.Alerts())
+	r.GET("/alerts/:id", h.cached(cacheNone), h.AlertByID())
+	r.POST("/alerts/:id/acknowledge", h.AcknowledgeAlert())
+	r.POST("/alerts/:id/resolve", h.ResolveAlert())
 }
 
 func (h *HTTP) Equipment() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var equipment []Equipment
-		result := h.db.Find(&equipment)
-		if result.Error != nil {
-			h.log.Sugar().Errorf("finding all equipment: %v", result.Error)
-			c.JSON(http.StatusInternalServerError, "Internal server error")
+		var errs []FieldError
+		filter := EquipmentFilter{AsOf: queryTime(c, "as_of", &errs)}
+		if len(errs) > 0 {
+			h.invalid(c, errs...)
+			return
+		}
+
+		if media := exportMediaType(c); media != "" {
+			h.export(c, media, "equipment", Equipment{}, func(write func(interface{}) error) error {
+				return h.equipment.EachEquipment(c.Request.Context(), customerScope(c), filter, func(e Equipment) error { return write(e) })
+			})
+			return
+		}
+
+		equipment, err := h.equipment.ListEquipment(c.Request.Context(), customerScope(c), filter)
+		if err != nil {
+			h.internalError(c, fmt.Errorf("finding all equipment: %w", err))
 			return
 		}
 		c.JSON(http.StatusOK, equipment)
 	}
 }
 
-func (h *HTTP) Events() gin.HandlerFunc {
+func (h *HTTP) EquipmentDistance() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		where := h.db.Model(&Event{})
-		after := c.Query("after")
-		if after != "" {
-			t, err := time.Parse(time.RFC3339, after)
+		ctx, scope, id := c.Request.Context(), customerScope(c), c.Param("id")
+
+		records, err := h.equipment.ListEquipment(ctx, scope, EquipmentFilter{EquipmentIDs: []string{id}})
+		if err != nil {
+			h.internalError(c, fmt.Errorf("finding equipment records: %w", err))
+			return
+		}
+		if len(records) == 0 {
+			h.problem(c, http.StatusNotFound, CodeEquipmentNotFound, fmt.Sprintf("Equipment %s was not found.", id))
+			return
+		}
+
+		waybills, err := h.waybills.ListWaybills(ctx, scope, WaybillFilter{EquipmentID: id})
+		if err != nil {
+			h.internalError(c, fmt.Errorf("finding equipment waybills: %w", err))
+			return
+		}
+		ids := make([]string, len(waybills))
+		for k, w := range waybills {
+			ids[k] = w.ID
+		}
+
+		// The waybills are already scoped, like their sightings.
+		events := []Event{}
+		if len(ids) > 0 {
+			events, err = h.events.ListEvents(ctx, Scope{}, EventFilter{WaybillIDs: ids})
 			if err != nil {
-				h.log.Sugar().Errorf("parsing query param after: %v", err)
-				c.JSON(http.StatusBadRequest, "could not parse query param after")
+				h.internalError(c, fmt.Errorf("finding equipment events: %w", err))
 				return
 			}
-			where = where.Where("events.posting_date > ?", t)
+		}
+		locations, ok := h.trackLocations(c, waybills, events)
+		if !ok {
+			return
 		}
 
-		var events []Event
-		result := where.Find(&events)
-		if result.Error != nil {
-			h.log.Sugar().Errorf("finding events: %v", result.Error)
-			c.JSON(http.StatusInternalServerError, "Internal Server Error")
+		byWaybill := make(map[string][]Event, len(waybills))
+		for _, e := range events {
+			byWaybill[e.WaybillID] = append(byWaybill[e.WaybillID], e)
+		}
+		distances := make([]Distance, len(waybills))
+		for k, w := range waybills {
+			distances[k] = WaybillDistance(w, byWaybill[w.ID], locations)
+		}
+		c.JSON(http.StatusOK, TotalDistance(id, distances))
+	}
+}
+
+func (h *HTTP) Events() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var errs []FieldError
+		filter := EventFilter{PostedAfter: queryTime(c, "after", &errs)}
+		if len(errs) > 0 {
+			h.invalid(c, errs...)
+			return
+		}
+
+		if media := exportMediaType(c); media != "" {
+			h.export(c, media, "events", Event{}, func(write func(interface{}) error) error {
+				return h.events.EachEvent(c.Request.Context(), customerScope(c), filter, func(e Event) error { return write(e) })
+			})
+			return
+		}
+
+		events, err := h.events.ListEvents(c.Request.Context(), customerScope(c), filter)
+		if err != nil {
+			h.internalError(c, fmt.Errorf("finding events: %w", err))
 			return
 		}
 		c.JSON(http.StatusOK, events)
@@ -116,11 +269,21 @@ func (h *HTTP) Events() gin.HandlerFunc {
 
 func (h *HTTP) Locations() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var locations []Location
-		result := h.db.Find(&locations)
-		if result.Error != nil {
-			h.log.Sugar().Errorf("finding all locations: %v", result.Error)
-			c.JSON(http.StatusInternalServerError, "Internal Server Error")
+		media := negotiate(c.GetHeader("Accept"), jsonMediaType, geoJSONMediaType, csvMediaType, ndjsonMediaType)
+		if media == csvMediaType || media == ndjsonMediaType {
+			h.export(c, media, "locations", Location{}, func(write func(interface{}) error) error {
+				return h.locations.EachLocation(c.Request.Context(), func(l Location) error { return write(l) })
+			})
+			return
+		}
+
+		locations, err := h.locations.ListLocations(c.Request.Context())
+		if err != nil {
+			h.internalError(c, fmt.Errorf("finding all locations: %w", err))
+			return
+		}
+		if media == geoJSONMediaType {
+			renderGeoJSON(c, http.StatusOK, LocationsGeoJSON(locations))
 			return
 		}
 		c.JSON(http.StatusOK, locations)
@@ -129,78 +292,132 @@ func (h *HTTP) Locations() gin.HandlerFunc {
 
 func (h *HTTP) Waybills() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var waybills []Waybill
-		result := h.db.Find(&waybills)
-		if result.Error != nil {
-			h.log.Sugar().Errorf("finding all waybills: %v", result.Error)
-			c.JSON(http.StatusInternalServerError, "Internal Server Error")
+		var errs []FieldError
+		include := queryIncludes(c, &errs)
+		media := exportMediaType(c)
+		if media != "" && len(include) > 0 {
+			errs = append(errs, FieldError{Name: "include", In: "query", Reason: "can't be used with " + media + " exports"})
+		}
+		if len(errs) > 0 {
+			h.invalid(c, errs...)
 			return
 		}
-		c.JSON(http.StatusOK, waybills)
+
+		filter := WaybillFilter{
+			WaybillNumber:      c.Query("waybill_number"),
+			BillOfLadingNumber: c.Query("bill_of_lading_number"),
+			EquipmentID:        c.Query("equipment_id"),
+		}
+		if media != "" {
+			h.exportWaybills(c, media, filter)
+			return
+		}
+
+		waybills, err := h.waybills.ListWaybills(c.Request.Context(), customerScope(c), filter)
+		if err != nil {
+			h.internalError(c, fmt.Errorf("finding all waybills: %w", err))
+			return
+		}
+
+		details, err := h.waybillDetails(c.Request.Context(), customerScope(c), waybills, include, requestVersion(c))
+		if err != nil {
+			h.internalError(c, fmt.Errorf("including waybill resources: %w", err))
+			return
+		}
+		c.JSON(http.StatusOK, details)
 	}
 }
 
 func (h *HTTP) WaybillsByID() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		id := c.Param("id")
-		if id == "" {
-			c.JSON(http.StatusBadRequest, "id not present")
+		var errs []FieldError
+		include := queryIncludes(c, &errs)
+		if len(errs) > 0 {
+			h.invalid(c, errs...)
 			return
 		}
 
-		var waybill Waybill
-		result := h.db.First(&waybill, id)
-		if result.Error != nil {
-			if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-				c.JSON(http.StatusNotFound, "Waybill not found")
-				return
-			}
-			h.log.Sugar().Errorf("finding waybill by id: %v", result.Error)
-			c.JSON(http.StatusInternalServerError, "Internal Server Error")
+		waybill, ok := h.findWaybill(c)
+		if !ok {
 			return
 		}
 
-		c.JSON(http.StatusOK, waybill)
+		details, err := h.waybillDetails(c.Request.Context(), customerScope(c), []Waybill{waybill}, include, requestVersion(c))
+		if err != nil {
+			h.internalError(c, fmt.Errorf("including waybill resources: %w", err))
+			return
+		}
+		c.JSON(http.StatusOK, details[0])
 	}
 }
 
 func (h *HTTP) WaybillEquipment() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		id := c.Param("id")
-		if id == "" {
-			c.JSON(http.StatusBadRequest, "id not present")
+		waybill, ok := h.findWaybill(c)
+		if !ok {
+			return
+		}
+
+		// A car may have belonged to another customer before or after this
+		// shipment; the scope keeps those records hidden.
+		records, err := h.equipment.ListEquipment(c.Request.Context(), customerScope(c), EquipmentFilter{EquipmentIDs: []string{waybill.EquipmentID}})
+		if err != nil {
+			h.internalError(c, fmt.Errorf("finding equipment records: %w", err))
+			return
+		}
+
+		// The last sighting bounds the shipment when no record covers the
+		// waybill date itself.
+		events, err := h.events.ListEvents(c.Request.Context(), Scope{}, EventFilter{WaybillID: waybill.ID})
+		if err != nil {
+			h.internalError(c, fmt.Errorf("finding last sighting: %w", err))
 			return
 		}
+		until := waybill.WaybillDate
+		if len(events) > 0 && events[len(events)-1].SightingDate.After(until) {
+			until = events[len(events)-1].SightingDate
+		}
 
-		var equipment []Equipment
-		h.db.Raw("SELECT equipment.* FROM waybills JOIN equipment on waybills.equipment_id = equipment.equipment_id WHERE waybills.id = ?", id).Scan(&equipment)
+		equipment := []Equipment{}
+		if e, ok := EquipmentAt(records, waybill.WaybillDate, until); ok {
+			equipment = append(equipment, e)
+		}
 
+		if media := exportMediaType(c); media != "" {
+			h.exportSlice(c, media, "equipment", equipment)
+			return
+		}
 		c.JSON(http.StatusOK, equipment)
 	}
 }
 
 func (h *HTTP) WaybillEvents() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		id := c.Param("id")
-		if id == "" {
-			c.JSON(http.StatusBadRequest, "id not present")
+		var errs []FieldError
+		after := queryTime(c, "after", &errs)
+		if len(errs) > 0 {
+			h.invalid(c, errs...)
 			return
 		}
 
-		where := h.db.Where("waybill_id = ?", id)
-		after := c.Query("after")
-		if after != "" {
-			t, err := time.Parse(time.RFC3339, after)
-			if err != nil {
-				h.log.Sugar().Errorf("parsing query param after: %v", err)
-				c.JSON(http.StatusBadRequest, "could not parse query param after")
-				return
-			}
-			where = where.Where("waybill_id = ? AND posting_date > ?", id, t)
+		waybill, ok := h.findWaybill(c)
+		if !ok {
+			return
+		}
+
+		filter := EventFilter{WaybillID: waybill.ID, PostedAfter: after}
+		if media := exportMediaType(c); media != "" {
+			h.export(c, media, "events", Event{}, func(write func(interface{}) error) error {
+				return h.events.EachEvent(c.Request.Context(), Scope{}, filter, func(e Event) error { return write(e) })
+			})
+			return
 		}
 
-		var events []Event
-		where.Find(&events)
+		events, err := h.events.ListEvents(c.Request.Context(), Scope{}, filter)
+		if err != nil {
+			h.internalError(c, fmt.Errorf("finding waybill events: %w", err))
+			return
+		}
 
 		c.JSON(http.StatusOK, events)
 	}
@@ -208,55 +425,35 @@ func (h *HTTP) WaybillEvents() gin.HandlerFunc {
 
 func (h *HTTP) WaybillLocations() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		id := c.Param("id")
-		if id == "" {
-			c.JSON(http.StatusBadRequest, "id not present")
+		waybill, ok := h.findWaybill(c)
+		if !ok {
 			return
 		}
 
-		var waybill Waybill
-		result := h.db.First(&waybill, id)
-		if result.Error != nil {
-			if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-				c.JSON(http.StatusNotFound, "Waybill not found")
-				return
-			}
-			h.log.Sugar().Errorf("finding waybill by id: %v", result.Error)
-			c.JSON(http.StatusInternalServerError, "Internal Server Error")
+		locations, err := h.locations.LocationsByID(c.Request.Context(), []string{waybill.OriginID, waybill.DestinationID})
+		if err != nil {
+			h.internalError(c, fmt.Errorf("finding waybill locations: %w", err))
 			return
 		}
 
-		var locations []Location
-		h.db.Raw("SELECT * FROM locations where id IN (?,?)", waybill.OriginID, waybill.DestinationID).Scan(&locations)
-
+		if media := exportMediaType(c); media != "" {
+			h.exportSlice(c, media, "locations", locations)
+			return
+		}
 		c.JSON(http.StatusOK, locations)
 	}
 }
 
 func (h *HTTP) WaybillRoute() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		id := c.Param("id")
-		if id == "" {
-			c.JSON(http.StatusBadRequest, "id not present")
+		waybill, ok := h.findWaybill(c)
+		if !ok {
 			return
 		}
 
-		var waybill Waybill
-		result := h.db.First(&waybill, id)
-		if result.Error != nil {
-			if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-				c.JSON(http.StatusNotFound, "Waybill not found")
-				return
-			}
-			h.log.Sugar().Errorf("finding waybill by id: %v", result.Error)
-			c.JSON(http.StatusInternalServerError, "Internal Server Error")
-			return
-		}
-
-		var route []RoutePart
-		if err := json.Unmarshal([]byte(waybill.Routes), &route); err != nil {
-			h.log.Sugar().Errorf("unmarshaling routes: %v", err)
-			c.JSON(http.StatusInternalServerError, "Internal Server Error")
+		route, err := waybill.DecodeRoutes()
+		if err != nil {
+			h.internalError(c, err)
 			return
 		}
 
@@ -266,31 +463,125 @@ func (h *HTTP) WaybillRoute() gin.HandlerFunc {
 
 func (h *HTTP) WaybillParties() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		id := c.Param("id")
-		if id == "" {
-			c.JSON(http.StatusBadRequest, "id not present")
+		waybill, ok := h.findWaybill(c)
+		if !ok {
 			return
 		}
 
-		var waybill Waybill
-		result := h.db.First(&waybill, id)
-		if result.Error != nil {
-			if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-				c.JSON(http.StatusNotFound, "Waybill not found")
-				return
-			}
-			h.log.Sugar().Errorf("finding waybill by id: %v", result.Error)
-			c.JSON(http.StatusInternalServerError, "Internal Server Error")
+		parties, err := waybill.DecodeParties()
+		if err != nil {
+			h.internalError(c, err)
 			return
 		}
 
-		var parties []Party
-		if err := json.Unmarshal([]byte(waybill.Parties), &parties); err != nil {
-			h.log.Sugar().Errorf("unmarshaling parties: %v", err)
-			c.JSON(http.StatusInternalServerError, "Internal Server Error")
+		c.JSON(http.StatusOK, parties)
+	}
+}
+
+func (h *HTTP) WaybillDistance() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		waybill, events, locations, ok := h.waybillTrack(c)
+		if !ok {
 			return
 		}
 
-		c.JSON(http.StatusOK, parties)
+		c.JSON(http.StatusOK, WaybillDistance(waybill, events, locations))
+	}
+}
+
+func (h *HTTP) WaybillTrack() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		waybill, events, locations, ok := h.waybillTrack(c)
+		if !ok {
+			return
+		}
+
+		renderGeoJSON(c, http.StatusOK, TrackGeoJSON(waybill, events, locations))
+	}
+}
+
+// exportWaybills streams the waybills matching filter. CSV rows keep the
+// route and parties as the JSON strings ingestion reads; NDJSON rows are the
+// waybills as the API version represents them.
+func (h *HTTP) exportWaybills(c *gin.Context, media string, filter WaybillFilter) {
+	ctx, scope := c.Request.Context(), customerScope(c)
+	h.export(c, media, "waybills", Waybill{}, func(write func(interface{}) error) error {
+		return h.waybills.EachWaybill(ctx, scope, filter, func(w Waybill) error {
+			if media == csvMediaType {
+				return write(w)
+			}
+			details, err := h.waybillDetails(ctx, scope, []Waybill{w}, nil, requestVersion(c))
+			if err != nil {
+				return err
+			}
+			return write(details[0])
+		})
+	})
+}
+
+// findWaybill loads the waybill named by the id path param, responding with
+// an error and returning false when it can't.
+func (h *HTTP) findWaybill(c *gin.Context) (Waybill, bool) {
+	id := c.Param("id")
+	if id == "" {
+		h.invalid(c, FieldError{Name: "id", In: "path", Reason: "is required"})
+		return Waybill{}, false
+	}
+
+	waybill, err := h.waybills.WaybillByID(c.Request.Context(), customerScope(c), id)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			h.problem(c, http.StatusNotFound, CodeWaybillNotFound, fmt.Sprintf("Waybill %s was not found.", id))
+			return Waybill{}, false
+		}
+		h.internalError(c, fmt.Errorf("finding waybill by id: %w", err))
+		return Waybill{}, false
+	}
+
+	return waybill, true
+}
+
+// waybillTrack loads a waybill with its sightings in order and the origin,
+// destination and sighting locations keyed by location ID.
+func (h *HTTP) waybillTrack(c *gin.Context) (Waybill, []Event, map[string]Location, bool) {
+	waybill, ok := h.findWaybill(c)
+	if !ok {
+		return Waybill{}, nil, nil, false
+	}
+
+	events, err := h.events.ListEvents(c.Request.Context(), Scope{}, EventFilter{WaybillID: waybill.ID})
+	if err != nil {
+		h.internalError(c, fmt.Errorf("finding waybill events: %w", err))
+		return Waybill{}, nil, nil, false
+	}
+
+	locations, ok := h.trackLocations(c, []Waybill{waybill}, events)
+	if !ok {
+		return Waybill{}, nil, nil, false
+	}
+	return waybill, events, locations, true
+}
+
+// trackLocations loads the origins, destinations and sighting locations of
+// waybills and their events, keyed by location ID.
+func (h *HTTP) trackLocations(c *gin.Context, waybills []Waybill, events []Event) (map[string]Location, bool) {
+	var ids []string
+	for _, w := range waybills {
+		ids = append(ids, w.OriginID, w.DestinationID)
+	}
+	for _, e := range events {
+		ids = append(ids, e.LocationID)
+	}
+
+	found, err := h.locations.LocationsByID(c.Request.Context(), ids)
+	if err != nil {
+		h.internalError(c, fmt.Errorf("finding waybill locations: %w", err))
+		return nil, false
+	}
+
+	locations := make(map[string]Location, len(found))
+	for _, l := range found {
+		locations[l.ID] = l
 	}
+	return locations, true
 }
@@ -0,0 +1,124 @@
+package app
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// VersionHeader selects the representation of resources whose shape has
+// changed. Responses echo the version served.
+const VersionHeader = "Telegraph-Version"
+
+// Headers announcing that a route is deprecated: RFC 9745's Deprecation, RFC
+// 8594's Sunset and a Link to the route replacing it.
+const (
+	DeprecationHeader = "Deprecation"
+	SunsetHeader      = "Sunset"
+	LinkHeader        = "Link"
+)
+
+const (
+	// APIVersion1 serves waybill routes and parties as the JSON strings they
+	// are stored as.
+	APIVersion1 = 1
+	// APIVersion2 serves waybill routes and parties as arrays.
+	APIVersion2 = 2
+
+	LatestAPIVersion = APIVersion2
+	// DefaultAPIVersion is served on unversioned routes without VersionHeader,
+	// so integrations written before versioning keep their shapes.
+	DefaultAPIVersion = APIVersion1
+
+	versionKey = "api_version"
+	prefixKey  = "api_prefix"
+)
+
+// Deprecation is when a group of routes was deprecated and when it will be
+// removed.
+type Deprecation struct {
+	Since  time.Time
+	Sunset time.Time
+	// Successor is the prefix serving the routes that replace them. Empty
+	// means the prefix of the version the request was served with.
+	Successor string
+}
+
+// apiGroup is a prefix the API's routes are served under.
+type apiGroup struct {
+	Prefix string
+	// Version is the version served, or 0 for the version the request asks
+	// for with VersionHeader.
+	Version     int
+	Deprecation *Deprecation
+}
+
+// apiGroups are the prefixes routes() registers the API under. Fixing a
+// response shape means adding a version; the root routes predate versioning
+// and only remain for existing integrations, which can pin to the version
+// they're served under its prefix.
+var apiGroups = []apiGroup{
+	{Prefix: versionPrefix(APIVersion1), Version: APIVersion1},
+	{Prefix: versionPrefix(APIVersion2), Version: APIVersion2},
+	{Prefix: "", Deprecation: &Deprecation{
+		Since:  time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+		Sunset: time.Date(2027, 4, 30, 0, 0, 0, 0, time.UTC),
+	}},
+}
+
+func versionPrefix(version int) string {
+	return "/v" + strconv.Itoa(version)
+}
+
+// versioned records the version and prefix a request is served with, taking
+// the version from VersionHeader on unversioned routes, and announces the
+// group's deprecation.
+func (h *HTTP) versioned(g apiGroup) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		version := g.Version
+		if version == 0 {
+			version = DefaultAPIVersion
+			if v := c.GetHeader(VersionHeader); v != "" {
+				n, err := strconv.Atoi(v)
+				if err != nil || n < APIVersion1 || n > LatestAPIVersion {
+					h.invalid(c, FieldError{Name: VersionHeader, In: "header", Reason: "must be 1 or 2"})
+					return
+				}
+				version = n
+			}
+		}
+
+		c.Set(versionKey, version)
+		c.Set(prefixKey, g.Prefix)
+		c.Header(VersionHeader, strconv.Itoa(version))
+
+		if d := g.Deprecation; d != nil {
+			successor := d.Successor
+			if successor == "" {
+				successor = versionPrefix(version)
+			}
+			link := successor + strings.TrimPrefix(c.Request.URL.Path, g.Prefix)
+			if c.Request.URL.RawQuery != "" {
+				link += "?" + c.Request.URL.RawQuery
+			}
+			c.Header(DeprecationHeader, fmt.Sprintf("@%d", d.Since.Unix()))
+			c.Header(SunsetHeader, d.Sunset.Format(http.TimeFormat))
+			c.Header(LinkHeader, fmt.Sprintf(`<%s>; rel="successor-version"`, link))
+		}
+		c.Next()
+	}
+}
+
+// requestVersion returns the version the request is served with.
+func requestVersion(c *gin.Context) int {
+	return c.GetInt(versionKey)
+}
+
+// requestPrefix returns the prefix of the route the request matched, for links
+// to other resources to stay within the same version.
+func requestPrefix(c *gin.Context) string {
+	return c.GetString(prefixKey)
+}
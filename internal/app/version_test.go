@@ -0,0 +1,116 @@
+package app_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/coreyvan/backend-takehome/internal/app"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestVersionedRoutes replays the GET cases in apiCases under /v1 and /v2 and
+// checks each responds as the unversioned route does when asked for that
+// version with the header, except for links and problem instances staying
+// within the version. The unversioned routes must say they're deprecated, and
+// the versioned ones mustn't.
+func TestVersionedRoutes(t *testing.T) {
+	srv, keys, _ := newTestAPI(t)
+
+	for _, tc := range apiCases {
+		if tc.route == "" || tc.method != "" || tc.route == "/openapi.json" || tc.route == "/docs" || tc.route == "/metrics" || tc.route == "/healthz" || tc.route == "/readyz" {
+			continue
+		}
+		version := tc.header[app.VersionHeader]
+		switch version {
+		case "":
+			version = "1"
+		case "1", "2":
+		default:
+			// The header is only read on unversioned routes.
+			continue
+		}
+		prefix := "/v" + version
+
+		t.Run(tc.name, func(t *testing.T) {
+			want := do(t, srv, keys, http.MethodGet, tc)
+			checkDeprecated(t, want, prefix+tc.path)
+
+			versioned := tc
+			versioned.path = prefix + tc.path
+			versioned.header = make(map[string]string)
+			for k, v := range tc.header {
+				if k != app.VersionHeader {
+					versioned.header[k] = v
+				}
+			}
+			got := do(t, srv, keys, http.MethodGet, versioned)
+
+			if v := got.header.Get(app.VersionHeader); v != version {
+				t.Errorf("%s header = %q, want %q", app.VersionHeader, v, version)
+			}
+			if d := got.header.Get(app.DeprecationHeader); d != "" {
+				t.Errorf("%s is deprecated: %s", versioned.path, d)
+			}
+
+			got.Body = unprefix(t, got.Body, prefix)
+			if got.Status != want.Status || got.ContentType != want.ContentType {
+				t.Errorf("%s = %d %s, want %d %s", versioned.path, got.Status, got.ContentType, want.Status, want.ContentType)
+			}
+			g, _ := json.Marshal(got.Body)
+			w, _ := json.Marshal(want.Body)
+			if string(g) != string(w) {
+				t.Errorf("%s differs from %s:\n%s", versioned.path, tc.path, diff(string(w), string(g)))
+			}
+		})
+	}
+}
+
+// checkDeprecated checks res announces its route is deprecated in favor of
+// successor.
+func checkDeprecated(t *testing.T, res response, successor string) {
+	t.Helper()
+
+	if res.header.Get(app.DeprecationHeader) == "" || res.header.Get(app.SunsetHeader) == "" {
+		t.Errorf("missing %s or %s header", app.DeprecationHeader, app.SunsetHeader)
+	}
+	if link, want := res.header.Get(app.LinkHeader), fmt.Sprintf(`<%s>; rel="successor-version"`, successor); link != want {
+		t.Errorf("%s header = %q, want %q", app.LinkHeader, link, want)
+	}
+}
+
+// paths names the response fields holding API paths.
+var paths = map[string]bool{"href": true, "instance": true}
+
+// unprefix strips the version prefix from the paths in a response body.
+func unprefix(t *testing.T, v interface{}, prefix string) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, field := range v {
+			if path, ok := field.(string); ok && paths[k] {
+				if !strings.HasPrefix(path, prefix+"/") {
+					t.Errorf("%s %s isn't under %s", k, path, prefix)
+				}
+				v[k] = strings.TrimPrefix(path, prefix)
+				continue
+			}
+			v[k] = unprefix(t, field, prefix)
+		}
+	case []interface{}:
+		for k := range v {
+			v[k] = unprefix(t, v[k], prefix)
+		}
+	}
+	return v
+}
+
+// unversioned strips the version prefix from a "METHOD /path" route.
+func unversioned(route string) string {
+	method, path, _ := strings.Cut(route, " ")
+	for _, prefix := range []string{"/v1/", "/v2/"} {
+		if strings.HasPrefix(path, prefix) {
+			return method + " " + strings.TrimPrefix(path, prefix[:3])
+		}
+	}
+	return route
+}
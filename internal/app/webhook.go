@@ -0,0 +1,411 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	SignatureHeader = "X-Telegraph-Signature"
+	TimestampHeader = "X-Telegraph-Timestamp"
+	DeliveryHeader  = "X-Telegraph-Delivery"
+)
+
+// ErrPrivateTarget is returned for webhook targets that resolve to loopback,
+// private, link-local (including cloud metadata services) or other non-public
+// addresses.
+var ErrPrivateTarget = errors.New("webhook target is not a public address")
+
+var privateNetworks = func() []*net.IPNet {
+	var nets []*net.IPNet
+	for _, cidr := range []string{
+		"0.0.0.0/8", "10.0.0.0/8", "100.64.0.0/10", "127.0.0.0/8", "169.254.0.0/16", "172.16.0.0/12",
+		"192.0.0.0/24", "192.168.0.0/16", "198.18.0.0/15", "224.0.0.0/4", "240.0.0.0/4",
+		"::/128", "::1/128", "fc00::/7", "fe80::/10", "ff00::/8",
+	} {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		nets = append(nets, n)
+	}
+	return nets
+}()
+
+func publicIP(ip net.IP) bool {
+	for _, n := range privateNetworks {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkTargetHost resolves a webhook target's host and returns
+// ErrPrivateTarget if any of its addresses isn't public.
+func checkTargetHost(ctx context.Context, host string) error {
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("resolving %s: %w", host, err)
+	}
+	for _, addr := range addrs {
+		if !publicIP(addr.IP) {
+			return fmt.Errorf("%s resolves to %s: %w", host, addr.IP, ErrPrivateTarget)
+		}
+	}
+	return nil
+}
+
+// NewWebhookClient returns the client deliveries are POSTed with. Unless
+// allowPrivate is set it refuses to connect to non-public addresses. The check
+// runs on the address being dialled, after DNS resolution and on every
+// redirect, so a target can't rebind to an internal address after subscribing.
+// Proxies from the environment aren't used, since they'd dial for us.
+func NewWebhookClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !publicIP(ip) {
+				return fmt.Errorf("dialing %s: %w", host, ErrPrivateTarget)
+			}
+			return nil
+		}
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 10 * time.Second,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+}
+
+// WebhookPayload is the JSON body POSTed to subscribers.
+type WebhookPayload struct {
+	Type           string `json:"type"`
+	SubscriptionID uint   `json:"subscription_id"`
+	Event          Event  `json:"event"`
+}
+
+// EnqueueDeliveries queues a delivery for every subscription matching each
+// event. Ingestion passes the events a load added or changed, so that
+// subscribers only hear about those. A corrected event already queued for a
+// subscription is queued again with its new payload and a fresh retry count.
+func EnqueueDeliveries(db *gorm.DB, events []Event) (int, error) {
+	var subs []Subscription
+	if err := db.Find(&subs).Error; err != nil {
+		return 0, fmt.Errorf("finding subscriptions: %w", err)
+	}
+	if len(subs) == 0 || len(events) == 0 {
+		return 0, nil
+	}
+
+	var equipment []Equipment
+	if err := db.Order("date_added").Find(&equipment).Error; err != nil {
+		return 0, fmt.Errorf("finding equipment: %w", err)
+	}
+	records := make(map[string][]Equipment)
+	for _, e := range equipment {
+		records[e.EquipmentID] = append(records[e.EquipmentID], e)
+	}
+
+	now := time.Now().UTC()
+	var deliveries []Delivery
+	for _, e := range events {
+		var customer string
+		if record, ok := EquipmentAt(records[e.EquipmentID], e.SightingDate, e.SightingDate); ok {
+			customer = record.Customer
+		}
+
+		for _, s := range subs {
+			if !s.Matches(e, customer) {
+				continue
+			}
+
+			payload, err := json.Marshal(WebhookPayload{
+				Type:           "event.posted",
+				SubscriptionID: s.ID,
+				Event:          e,
+			})
+			if err != nil {
+				return 0, fmt.Errorf("marshaling payload: %w", err)
+			}
+
+			deliveries = append(deliveries, Delivery{
+				SubscriptionID: s.ID,
+				EventID:        e.ID,
+				Payload:        string(payload),
+				Status:         DeliveryPending,
+				NextAttemptAt:  now,
+			})
+		}
+	}
+	if len(deliveries) == 0 {
+		return 0, nil
+	}
+
+	result := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "subscription_id"}, {Name: "event_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"payload", "status", "attempts", "next_attempt_at", "last_error", "updated_at"}),
+	}).CreateInBatches(&deliveries, 500)
+	if result.Error != nil {
+		return 0, fmt.Errorf("creating deliveries: %w", result.Error)
+	}
+
+	return int(result.RowsAffected), nil
+}
+
+// Sign returns the signature sent in SignatureHeader: a hex HMAC-SHA256 over
+// the timestamp, a dot and the body, keyed with the subscription secret.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature checks a delivery's signature in constant time.
+func VerifySignature(secret, timestamp, signature string, body []byte) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// DeliveryWorker POSTs pending deliveries to their subscribers, retrying
+// failures with exponential backoff and dead-lettering deliveries that run out
+// of attempts. Several workers may share a database: each claims a delivery
+// for Lease before sending it.
+type DeliveryWorker struct {
+	db     *gorm.DB
+	log    *zap.Logger
+	client *http.Client
+
+	Interval    time.Duration
+	BatchSize   int
+	MaxAttempts int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	Lease       time.Duration
+}
+
+func NewDeliveryWorker(db *gorm.DB, log *zap.Logger, client *http.Client) *DeliveryWorker {
+	if client == nil {
+		client = NewWebhookClient(10*time.Second, false)
+	}
+
+	// A claimed delivery is retried by another worker if this one dies, but
+	// not while its POST may still be in flight.
+	lease := time.Minute
+	if 2*client.Timeout > lease {
+		lease = 2 * client.Timeout
+	}
+
+	return &DeliveryWorker{
+		db:          db,
+		log:         log,
+		client:      client,
+		Interval:    5 * time.Second,
+		BatchSize:   100,
+		MaxAttempts: 8,
+		BaseBackoff: 30 * time.Second,
+		MaxBackoff:  time.Hour,
+		Lease:       lease,
+	}
+}
+
+// Run delivers due deliveries every Interval until ctx is cancelled.
+func (w *DeliveryWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := w.DeliverDue(ctx); err != nil {
+			w.log.Sugar().Errorf("delivering webhooks: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverDue attempts every pending delivery whose next attempt is due and
+// returns how many were attempted. Deliveries another worker claimed first are
+// skipped.
+func (w *DeliveryWorker) DeliverDue(ctx context.Context) (int, error) {
+	now := time.Now().UTC()
+	var due []Delivery
+	result := w.db.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", DeliveryPending, now).
+		Order("next_attempt_at, id").
+		Limit(w.BatchSize).
+		Find(&due)
+	if result.Error != nil {
+		return 0, fmt.Errorf("finding due deliveries: %w", result.Error)
+	}
+	if len(due) == 0 {
+		return 0, nil
+	}
+
+	attempted := 0
+	subs := make(map[uint]Subscription)
+	for _, d := range due {
+		if ctx.Err() != nil {
+			return attempted, ctx.Err()
+		}
+
+		claimed, err := w.claim(ctx, d, now)
+		if err != nil {
+			return attempted, fmt.Errorf("claiming delivery %d: %w", d.ID, err)
+		}
+		if !claimed {
+			continue
+		}
+
+		s, ok := subs[d.SubscriptionID]
+		if !ok {
+			if err := w.db.WithContext(ctx).First(&s, d.SubscriptionID).Error; err != nil {
+				return attempted, fmt.Errorf("finding subscription %d: %w", d.SubscriptionID, err)
+			}
+			subs[d.SubscriptionID] = s
+		}
+
+		if err := w.attempt(ctx, s, d); err != nil {
+			return attempted, fmt.Errorf("recording delivery %d: %w", d.ID, err)
+		}
+		attempted++
+	}
+
+	return attempted, nil
+}
+
+// claim pushes a due delivery's next attempt back by Lease, reporting false
+// if another worker already has.
+func (w *DeliveryWorker) claim(ctx context.Context, d Delivery, now time.Time) (bool, error) {
+	result := w.db.WithContext(ctx).Model(&Delivery{}).
+		Where("id = ? AND status = ? AND attempts = ? AND next_attempt_at <= ?", d.ID, DeliveryPending, d.Attempts, now).
+		Update("next_attempt_at", now.Add(w.Lease))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (w *DeliveryWorker) attempt(ctx context.Context, s Subscription, d Delivery) error {
+	started := time.Now().UTC()
+	code, postErr := w.post(ctx, s, d)
+
+	d.Attempts++
+	record := DeliveryAttempt{
+		DeliveryID:  d.ID,
+		Attempt:     d.Attempts,
+		StatusCode:  code,
+		DurationMS:  time.Since(started).Milliseconds(),
+		AttemptedAt: started,
+	}
+	if postErr != nil {
+		record.Error = postErr.Error()
+		d.LastError = postErr.Error()
+	}
+
+	return w.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&record).Error; err != nil {
+			return fmt.Errorf("logging attempt: %w", err)
+		}
+
+		switch {
+		case postErr == nil:
+			d.Status = DeliveryDelivered
+			d.LastError = ""
+		case d.Attempts >= w.MaxAttempts:
+			d.Status = DeliveryDead
+			dead := DeadLetter{
+				DeliveryID:     d.ID,
+				SubscriptionID: d.SubscriptionID,
+				EventID:        d.EventID,
+				Payload:        d.Payload,
+				LastError:      d.LastError,
+			}
+			// A corrected event can exhaust its retries again.
+			upsert := clause.OnConflict{
+				Columns:   []clause.Column{{Name: "delivery_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{"payload", "last_error", "created_at"}),
+			}
+			if err := tx.Clauses(upsert).Create(&dead).Error; err != nil {
+				return fmt.Errorf("dead-lettering: %w", err)
+			}
+		default:
+			d.NextAttemptAt = started.Add(w.backoff(d.Attempts))
+		}
+
+		return tx.Model(&Delivery{}).Where("id = ?", d.ID).Updates(map[string]interface{}{
+			"status":          d.Status,
+			"attempts":        d.Attempts,
+			"next_attempt_at": d.NextAttemptAt,
+			"last_error":      d.LastError,
+		}).Error
+	})
+}
+
+// post sends the delivery and returns the response status code, failing on
+// anything but a 2xx.
+func (w *DeliveryWorker) post(ctx context.Context, s Subscription, d Delivery) (int, error) {
+	body := []byte(d.Payload)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.TargetURL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("building request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, Sign(s.Secret, timestamp, body))
+	req.Header.Set(DeliveryHeader, strconv.FormatUint(uint64(d.ID), 10))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("posting: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("subscriber responded %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff doubles BaseBackoff for each failed attempt, capped at MaxBackoff.
+func (w *DeliveryWorker) backoff(attempts int) time.Duration {
+	d := w.BaseBackoff
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if d >= w.MaxBackoff {
+			return w.MaxBackoff
+		}
+	}
+	return d
+}
@@ -0,0 +1,65 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"github.com/coreyvan/backend-takehome/internal/app"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWebhookClientRefusesPrivateAddresses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(srv.Close)
+
+	if _, err := app.NewWebhookClient(time.Second, false).Get(srv.URL); !errors.Is(err, app.ErrPrivateTarget) {
+		t.Errorf("posting to %s: err = %v, want ErrPrivateTarget", srv.URL, err)
+	}
+
+	res, err := app.NewWebhookClient(time.Second, true).Get(srv.URL)
+	if err != nil {
+		t.Fatalf("posting to %s with private targets allowed: %v", srv.URL, err)
+	}
+	res.Body.Close()
+}
+
+// TestDeliverDueClaims checks a worker skips a delivery another worker is
+// still sending.
+func TestDeliverDueClaims(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	client := app.NewWebhookClient(time.Second, true)
+	other := app.NewDeliveryWorker(db, zap.NewNop(), client)
+
+	posts := 0
+	var concurrent int
+	var concurrentErr error
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posts++
+		concurrent, concurrentErr = other.DeliverDue(ctx)
+	}))
+	t.Cleanup(srv.Close)
+
+	sub := app.Subscription{TargetURL: srv.URL, Secret: "secret"}
+	if err := db.Create(&sub).Error; err != nil {
+		t.Fatal(err)
+	}
+	delivery := app.Delivery{SubscriptionID: sub.ID, EventID: "43128", Payload: "{}", Status: app.DeliveryPending, NextAttemptAt: time.Now().UTC().Add(-time.Minute)}
+	if err := db.Create(&delivery).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	attempted, err := app.NewDeliveryWorker(db, zap.NewNop(), client).DeliverDue(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attempted != 1 || posts != 1 {
+		t.Errorf("attempted %d deliveries in %d posts, want 1", attempted, posts)
+	}
+	if concurrent != 0 || concurrentErr != nil {
+		t.Errorf("a second worker attempted %d deliveries (err %v) while the first was sending", concurrent, concurrentErr)
+	}
+}
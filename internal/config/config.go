@@ -0,0 +1,457 @@
+// Package config loads settings shared by telegraph-api and telegraph-cli from
+// defaults, an optional YAML file, environment variables and flags, in
+// increasing order of precedence.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const redacted = "******"
+
+// sqliteScheme prefixes DSNs that select the SQLite backend.
+const sqliteScheme = "sqlite:"
+
+// Stores the API can read tracking data from.
+const (
+	StoreDatabase = "database"
+	StoreMemory   = "memory"
+)
+
+var passwordParam = regexp.MustCompile(`(^|\s)password\s*=\s*('(?:[^'\\]|\\.)*'|\S+)`)
+
+type Config struct {
+	DB        DB       `yaml:"db"`
+	HTTP      HTTP     `yaml:"http"`
+	GRPC      GRPC     `yaml:"grpc"`
+	Cache     Cache    `yaml:"cache"`
+	Webhooks  Webhooks `yaml:"webhooks"`
+	Alerts    string   `yaml:"alert_rules"`
+	Demurrage string   `yaml:"demurrage_tariffs"`
+	Store     string   `yaml:"store"`
+	Fixtures  string   `yaml:"fixtures"`
+	// MemoryAPIKey is the unrestricted key the memory store accepts. Without
+	// one the API mints a key at startup.
+	MemoryAPIKey string `yaml:"memory_api_key"`
+}
+
+// DB configures the database connection. DSN, when set, is used as is and
+// takes precedence over the individual connection fields. A DSN starting with
+// sqlite: selects SQLite instead of Postgres.
+type DB struct {
+	DSN             string        `yaml:"dsn"`
+	Host            string        `yaml:"host"`
+	Port            int           `yaml:"port"`
+	User            string        `yaml:"user"`
+	Password        string        `yaml:"password"`
+	Name            string        `yaml:"name"`
+	SSLMode         string        `yaml:"sslmode"`
+	ConnectTimeout  time.Duration `yaml:"connect_timeout"`
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+	// StartupTimeout is how long the API retries reaching the database when
+	// it starts. Zero tries once.
+	StartupTimeout time.Duration `yaml:"startup_timeout"`
+}
+
+// HTTP configures the API server. WriteTimeout defaults to zero (none) since
+// a write deadline would cut off the event streams.
+type HTTP struct {
+	Port         string        `yaml:"port"`
+	ReadTimeout  time.Duration `yaml:"read_timeout"`
+	WriteTimeout time.Duration `yaml:"write_timeout"`
+	IdleTimeout  time.Duration `yaml:"idle_timeout"`
+	// ShutdownTimeout is how long in-flight requests get to finish once the
+	// server is asked to stop.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	// MaxIngestAge fails /readyz when the data was last ingested longer ago.
+	// Zero doesn't check.
+	MaxIngestAge time.Duration `yaml:"max_ingest_age"`
+	// GraphQLMaxDepth and GraphQLMaxComplexity bound the queries /graphql
+	// runs, so nested lists can't fan out into unbounded reads.
+	GraphQLMaxDepth      int `yaml:"graphql_max_depth"`
+	GraphQLMaxComplexity int `yaml:"graphql_max_complexity"`
+}
+
+// GRPC configures the gRPC server run alongside the HTTP API.
+type GRPC struct {
+	Port string `yaml:"port"`
+}
+
+// Cache configures the read-through cache in front of waybill and location
+// reads. A zero Size disables it.
+type Cache struct {
+	Size        int           `yaml:"size"`
+	TTL         time.Duration `yaml:"ttl"`
+	RevisionTTL time.Duration `yaml:"revision_ttl"`
+}
+
+type Webhooks struct {
+	Interval    time.Duration `yaml:"interval"`
+	Timeout     time.Duration `yaml:"timeout"`
+	MaxAttempts int           `yaml:"max_attempts"`
+	// AllowPrivateTargets lets webhooks go to loopback and private addresses,
+	// as a local sink needs. Leave it off where API keys aren't trusted.
+	AllowPrivateTargets bool `yaml:"allow_private_targets"`
+}
+
+// Default returns the settings used when nothing else is configured. There is
+// deliberately no default password.
+func Default() Config {
+	return Config{
+		DB: DB{
+			Host:            "localhost",
+			Port:            5432,
+			User:            "candidate",
+			Name:            "telegraph",
+			SSLMode:         "disable",
+			ConnectTimeout:  5 * time.Second,
+			MaxOpenConns:    10,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: 30 * time.Minute,
+			StartupTimeout:  time.Minute,
+		},
+		HTTP: HTTP{
+			Port:                 "3000",
+			ReadTimeout:          15 * time.Second,
+			IdleTimeout:          2 * time.Minute,
+			ShutdownTimeout:      15 * time.Second,
+			GraphQLMaxDepth:      8,
+			GraphQLMaxComplexity: 5000,
+		},
+		GRPC: GRPC{
+			Port: "3001",
+		},
+		Cache: Cache{
+			Size:        10000,
+			TTL:         5 * time.Minute,
+			RevisionTTL: time.Second,
+		},
+		Webhooks: Webhooks{
+			Interval:    5 * time.Second,
+			Timeout:     10 * time.Second,
+			MaxAttempts: 8,
+		},
+		Alerts:    "alerts.yml",
+		Demurrage: "demurrage.yml",
+		Store:     StoreDatabase,
+		Fixtures:  "data",
+	}
+}
+
+// option binds one setting to its flag and environment variable.
+type option struct {
+	flag  string
+	env   string
+	usage string
+	field func(*Config) interface{}
+}
+
+var options = []option{
+	{"dsn", "TELEGRAPH_DSN", "database connection string, overriding the individual db settings", func(c *Config) interface{} { return &c.DB.DSN }},
+	{"db-host", "PGHOST", "database host", func(c *Config) interface{} { return &c.DB.Host }},
+	{"db-port", "PGPORT", "database port", func(c *Config) interface{} { return &c.DB.Port }},
+	{"db-user", "PGUSER", "database user", func(c *Config) interface{} { return &c.DB.User }},
+	{"db-password", "PGPASSWORD", "database password", func(c *Config) interface{} { return &c.DB.Password }},
+	{"db-name", "PGDATABASE", "database name", func(c *Config) interface{} { return &c.DB.Name }},
+	{"db-sslmode", "PGSSLMODE", "database sslmode", func(c *Config) interface{} { return &c.DB.SSLMode }},
+	{"db-connect-timeout", "TELEGRAPH_DB_CONNECT_TIMEOUT", "database connect timeout", func(c *Config) interface{} { return &c.DB.ConnectTimeout }},
+	{"db-max-open-conns", "TELEGRAPH_DB_MAX_OPEN_CONNS", "maximum open database connections", func(c *Config) interface{} { return &c.DB.MaxOpenConns }},
+	{"db-max-idle-conns", "TELEGRAPH_DB_MAX_IDLE_CONNS", "maximum idle database connections", func(c *Config) interface{} { return &c.DB.MaxIdleConns }},
+	{"db-conn-max-lifetime", "TELEGRAPH_DB_CONN_MAX_LIFETIME", "maximum lifetime of a database connection", func(c *Config) interface{} { return &c.DB.ConnMaxLifetime }},
+	{"db-startup-timeout", "TELEGRAPH_DB_STARTUP_TIMEOUT", "how long the API waits for the database at startup", func(c *Config) interface{} { return &c.DB.StartupTimeout }},
+	{"port", "TELEGRAPH_PORT", "HTTP port for the API", func(c *Config) interface{} { return &c.HTTP.Port }},
+	{"http-read-timeout", "TELEGRAPH_HTTP_READ_TIMEOUT", "HTTP request read timeout", func(c *Config) interface{} { return &c.HTTP.ReadTimeout }},
+	{"http-write-timeout", "TELEGRAPH_HTTP_WRITE_TIMEOUT", "HTTP response write timeout", func(c *Config) interface{} { return &c.HTTP.WriteTimeout }},
+	{"http-idle-timeout", "TELEGRAPH_HTTP_IDLE_TIMEOUT", "HTTP keep-alive idle timeout", func(c *Config) interface{} { return &c.HTTP.IdleTimeout }},
+	{"http-shutdown-timeout", "TELEGRAPH_HTTP_SHUTDOWN_TIMEOUT", "how long in-flight requests get to finish on shutdown", func(c *Config) interface{} { return &c.HTTP.ShutdownTimeout }},
+	{"max-ingest-age", "TELEGRAPH_MAX_INGEST_AGE", "oldest ingest run /readyz accepts, or 0 not to check", func(c *Config) interface{} { return &c.HTTP.MaxIngestAge }},
+	{"graphql-max-depth", "TELEGRAPH_GRAPHQL_MAX_DEPTH", "deepest field nesting a GraphQL query may select", func(c *Config) interface{} { return &c.HTTP.GraphQLMaxDepth }},
+	{"graphql-max-complexity", "TELEGRAPH_GRAPHQL_MAX_COMPLEXITY", "highest estimated field count a GraphQL query may resolve", func(c *Config) interface{} { return &c.HTTP.GraphQLMaxComplexity }},
+	{"grpc-port", "TELEGRAPH_GRPC_PORT", "gRPC port for the API", func(c *Config) interface{} { return &c.GRPC.Port }},
+	{"cache-size", "TELEGRAPH_CACHE_SIZE", "entries the read cache holds, or 0 to disable it", func(c *Config) interface{} { return &c.Cache.Size }},
+	{"cache-ttl", "TELEGRAPH_CACHE_TTL", "how long the read cache keeps an entry", func(c *Config) interface{} { return &c.Cache.TTL }},
+	{"cache-revision-ttl", "TELEGRAPH_CACHE_REVISION_TTL", "how long the read cache reuses the latest ingest run before checking again", func(c *Config) interface{} { return &c.Cache.RevisionTTL }},
+	{"webhook-interval", "TELEGRAPH_WEBHOOK_INTERVAL", "how often pending webhook deliveries are sent", func(c *Config) interface{} { return &c.Webhooks.Interval }},
+	{"webhook-timeout", "TELEGRAPH_WEBHOOK_TIMEOUT", "timeout for each webhook delivery", func(c *Config) interface{} { return &c.Webhooks.Timeout }},
+	{"webhook-max-attempts", "TELEGRAPH_WEBHOOK_MAX_ATTEMPTS", "webhook delivery attempts before dead-lettering", func(c *Config) interface{} { return &c.Webhooks.MaxAttempts }},
+	{"webhook-allow-private-targets", "TELEGRAPH_WEBHOOK_ALLOW_PRIVATE_TARGETS", "let webhooks go to loopback and private addresses", func(c *Config) interface{} { return &c.Webhooks.AllowPrivateTargets }},
+	{"alert-rules", "TELEGRAPH_ALERT_RULES", "path to the alert rules file", func(c *Config) interface{} { return &c.Alerts }},
+	{"demurrage-tariffs", "TELEGRAPH_DEMURRAGE_TARIFFS", "path to the demurrage tariffs file", func(c *Config) interface{} { return &c.Demurrage }},
+	{"store", "TELEGRAPH_STORE", "where the API reads tracking data from: database or memory", func(c *Config) interface{} { return &c.Store }},
+	{"fixtures", "TELEGRAPH_FIXTURES", "directory of CSV files loaded by the memory store", func(c *Config) interface{} { return &c.Fixtures }},
+	{"memory-api-key", "TELEGRAPH_MEMORY_API_KEY", "unrestricted API key the memory store accepts, instead of minting one", func(c *Config) interface{} { return &c.MemoryAPIKey }},
+}
+
+// Load builds the configuration for a binary from args (without the program
+// name) and returns it along with the remaining positional arguments. The
+// config file is named by --config or TELEGRAPH_CONFIG.
+func Load(name string, args []string) (Config, []string, error) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	path := fs.String("config", os.Getenv("TELEGRAPH_CONFIG"), "path to a YAML config file")
+
+	var fromFlags Config
+	for _, o := range options {
+		usage := fmt.Sprintf("%s (env %s)", o.usage, o.env)
+		switch v := o.field(&fromFlags).(type) {
+		case *string:
+			fs.StringVar(v, o.flag, "", usage)
+		case *int:
+			fs.IntVar(v, o.flag, 0, usage)
+		case *time.Duration:
+			fs.DurationVar(v, o.flag, 0, usage)
+		case *bool:
+			fs.BoolVar(v, o.flag, false, usage)
+		}
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return Config{}, nil, err
+	}
+
+	cfg := Default()
+	if *path != "" {
+		if err := cfg.loadFile(*path); err != nil {
+			return Config{}, nil, err
+		}
+	}
+
+	for _, o := range options {
+		value, ok := os.LookupEnv(o.env)
+		if !ok || value == "" {
+			continue
+		}
+		if err := set(o.field(&cfg), value); err != nil {
+			return Config{}, nil, fmt.Errorf("parsing %s: %w", o.env, err)
+		}
+	}
+
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	for _, o := range options {
+		if set[o.flag] {
+			copyField(o.field(&cfg), o.field(&fromFlags))
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, nil, err
+	}
+
+	return cfg, fs.Args(), nil
+}
+
+func (c *Config) loadFile(path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+	if err := yaml.UnmarshalStrict(b, c); err != nil {
+		return fmt.Errorf("parsing config file: %w", err)
+	}
+	return nil
+}
+
+func (c Config) Validate() error {
+	var errs []string
+	if c.DB.DSN == "" {
+		if c.DB.Host == "" {
+			errs = append(errs, "db host is required")
+		}
+		if c.DB.Port <= 0 || c.DB.Port > 65535 {
+			errs = append(errs, "db port must be between 1 and 65535")
+		}
+		if c.DB.User == "" {
+			errs = append(errs, "db user is required")
+		}
+		if c.DB.Name == "" {
+			errs = append(errs, "db name is required")
+		}
+	}
+	if path, ok := c.DB.SQLitePath(); ok && path == "" {
+		errs = append(errs, "sqlite dsn needs a file path, as in sqlite://telegraph.db")
+	}
+	if c.DB.ConnectTimeout < 0 || c.DB.ConnMaxLifetime < 0 || c.DB.StartupTimeout < 0 {
+		errs = append(errs, "db timeouts can't be negative")
+	}
+	if c.DB.MaxOpenConns < 0 || c.DB.MaxIdleConns < 0 {
+		errs = append(errs, "db pool sizes can't be negative")
+	}
+	if port, err := strconv.Atoi(c.HTTP.Port); err != nil || port <= 0 || port > 65535 {
+		errs = append(errs, "http port must be between 1 and 65535")
+	}
+	if c.HTTP.ReadTimeout < 0 || c.HTTP.WriteTimeout < 0 || c.HTTP.IdleTimeout < 0 || c.HTTP.ShutdownTimeout < 0 {
+		errs = append(errs, "http timeouts can't be negative")
+	}
+	if c.HTTP.MaxIngestAge < 0 {
+		errs = append(errs, "max ingest age can't be negative")
+	}
+	if c.HTTP.GraphQLMaxDepth <= 0 || c.HTTP.GraphQLMaxComplexity <= 0 {
+		errs = append(errs, "graphql max depth and complexity must be positive")
+	}
+	if port, err := strconv.Atoi(c.GRPC.Port); err != nil || port <= 0 || port > 65535 {
+		errs = append(errs, "grpc port must be between 1 and 65535")
+	} else if c.GRPC.Port == c.HTTP.Port {
+		errs = append(errs, "grpc and http ports must differ")
+	}
+	if c.Cache.Size < 0 || c.Cache.TTL < 0 || c.Cache.RevisionTTL < 0 {
+		errs = append(errs, "cache size and ttls can't be negative")
+	}
+	if c.Webhooks.Interval <= 0 || c.Webhooks.Timeout <= 0 {
+		errs = append(errs, "webhook interval and timeout must be positive")
+	}
+	if c.Webhooks.MaxAttempts <= 0 {
+		errs = append(errs, "webhook max attempts must be positive")
+	}
+	if c.Store != StoreDatabase && c.Store != StoreMemory {
+		errs = append(errs, fmt.Sprintf("store must be %s or %s", StoreDatabase, StoreMemory))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid config: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// ConnectionString returns the DSN to connect with, built from the individual
+// settings unless DSN is set.
+func (d DB) ConnectionString() string {
+	if d.DSN != "" {
+		return d.DSN
+	}
+
+	params := []string{
+		"host=" + quote(d.Host),
+		"port=" + strconv.Itoa(d.Port),
+		"user=" + quote(d.User),
+		"dbname=" + quote(d.Name),
+	}
+	if d.Password != "" {
+		params = append(params, "password="+quote(d.Password))
+	}
+	if d.SSLMode != "" {
+		params = append(params, "sslmode="+quote(d.SSLMode))
+	}
+	if d.ConnectTimeout > 0 {
+		seconds := int(d.ConnectTimeout.Round(time.Second).Seconds())
+		if seconds < 1 {
+			seconds = 1
+		}
+		params = append(params, "connect_timeout="+strconv.Itoa(seconds))
+	}
+
+	return strings.Join(params, " ")
+}
+
+// SQLitePath returns the database file named by a SQLite DSN such as
+// sqlite://telegraph.db or sqlite:///var/lib/telegraph.db, and false when the
+// DSN selects Postgres. Query params are kept for the driver.
+func (d DB) SQLitePath() (string, bool) {
+	if !strings.HasPrefix(d.DSN, sqliteScheme) {
+		return "", false
+	}
+	path := strings.TrimPrefix(d.DSN, sqliteScheme)
+	return strings.TrimPrefix(path, "//"), true
+}
+
+// String redacts the password so a DB can be logged safely.
+func (d DB) String() string {
+	return redactDSN(d.Redacted().ConnectionString())
+}
+
+// Redacted returns a copy of the settings with secrets masked.
+func (d DB) Redacted() DB {
+	if d.Password != "" {
+		d.Password = redacted
+	}
+	d.DSN = redactDSN(d.DSN)
+	return d
+}
+
+// Redacted returns a copy of the configuration safe to log.
+func (c Config) Redacted() Config {
+	c.DB = c.DB.Redacted()
+	if c.MemoryAPIKey != "" {
+		c.MemoryAPIKey = redacted
+	}
+	return c
+}
+
+// redactDSN masks the password in URL or keyword/value connection strings.
+func redactDSN(dsn string) string {
+	if dsn == "" {
+		return dsn
+	}
+
+	if u, err := url.Parse(dsn); err == nil && u.Scheme != "" && u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			// url escapes the mask, so it's swapped in for a plain placeholder.
+			u.User = url.UserPassword(u.User.Username(), "REDACTED")
+			return strings.Replace(u.String(), ":REDACTED@", ":"+redacted+"@", 1)
+		}
+		return dsn
+	}
+
+	return passwordParam.ReplaceAllString(dsn, "${1}password="+redacted)
+}
+
+// quote escapes a keyword/value connection string value.
+func quote(v string) string {
+	if v != "" && !strings.ContainsAny(v, ` '\`) {
+		return v
+	}
+	v = strings.ReplaceAll(v, `\`, `\\`)
+	v = strings.ReplaceAll(v, `'`, `\'`)
+	return "'" + v + "'"
+}
+
+func set(field interface{}, value string) error {
+	switch v := field.(type) {
+	case *string:
+		*v = value
+	case *int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		*v = n
+	case *time.Duration:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		*v = d
+	case *bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		*v = b
+	default:
+		return errors.New("unsupported setting type")
+	}
+	return nil
+}
+
+func copyField(dst, src interface{}) {
+	switch d := dst.(type) {
+	case *string:
+		*d = *src.(*string)
+	case *int:
+		*d = *src.(*int)
+	case *time.Duration:
+		*d = *src.(*time.Duration)
+	case *bool:
+		*d = *src.(*bool)
+	}
+}
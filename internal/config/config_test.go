@@ -0,0 +1,111 @@
+package config_test
+
+import (
+	"github.com/coreyvan/backend-takehome/internal/config"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestLoadPrecedence checks each source overrides the ones before it:
+// defaults, then the YAML file, then environment variables, then flags.
+func TestLoadPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "telegraph.yml")
+	yml := `
+http:
+  port: "4000"
+  idle_timeout: 1m
+webhooks:
+  interval: 1s
+  max_attempts: 3
+`
+	if err := os.WriteFile(path, []byte(yml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TELEGRAPH_CONFIG", "")
+	t.Setenv("TELEGRAPH_PORT", "5000")
+	t.Setenv("TELEGRAPH_WEBHOOK_MAX_ATTEMPTS", "4")
+	t.Setenv("TELEGRAPH_HTTP_IDLE_TIMEOUT", "")
+
+	cfg, args, err := config.Load("test", []string{"--config", path, "--webhook-max-attempts", "6", "8080"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name      string
+		got, want interface{}
+	}{
+		{"default", cfg.Webhooks.Timeout, config.Default().Webhooks.Timeout},
+		{"yaml over default", cfg.Webhooks.Interval, time.Second},
+		{"yaml over empty env", cfg.HTTP.IdleTimeout, time.Minute},
+		{"env over yaml", cfg.HTTP.Port, "5000"},
+		{"flag over env", cfg.Webhooks.MaxAttempts, 6},
+	} {
+		if tc.got != tc.want {
+			t.Errorf("%s: got %v, want %v", tc.name, tc.got, tc.want)
+		}
+	}
+	if len(args) != 1 || args[0] != "8080" {
+		t.Errorf("args = %v, want the positional port", args)
+	}
+}
+
+func TestLoadRejectsInvalidValues(t *testing.T) {
+	t.Setenv("TELEGRAPH_CONFIG", "")
+	t.Setenv("TELEGRAPH_WEBHOOK_MAX_ATTEMPTS", "lots")
+	if _, _, err := config.Load("test", nil); err == nil || !strings.Contains(err.Error(), "TELEGRAPH_WEBHOOK_MAX_ATTEMPTS") {
+		t.Errorf("bad env value: err = %v", err)
+	}
+
+	t.Setenv("TELEGRAPH_WEBHOOK_MAX_ATTEMPTS", "")
+	if _, _, err := config.Load("test", []string{"--port", "70000"}); err == nil {
+		t.Error("an http port out of range was accepted")
+	}
+	if _, _, err := config.Load("test", []string{"--store", "redis"}); err == nil {
+		t.Error("an unknown store was accepted")
+	}
+}
+
+func TestRedacted(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		db   config.DB
+	}{
+		{"password field", config.DB{Host: "db", Port: 5432, User: "u", Name: "telegraph", Password: "s3cret"}},
+		{"quoted password field", config.DB{Host: "db", Port: 5432, User: "u", Name: "telegraph", Password: "s3 cret'"}},
+		{"url dsn", config.DB{DSN: "postgres://u:s3cret@db:5432/telegraph?sslmode=disable"}},
+		{"keyword dsn", config.DB{DSN: "host=db user=u password=s3cret dbname=telegraph"}},
+		{"quoted keyword dsn", config.DB{DSN: "host=db password='s3 cret' dbname=telegraph"}},
+	} {
+		for format, s := range map[string]string{
+			"String":   tc.db.String(),
+			"Redacted": tc.db.Redacted().DSN + tc.db.Redacted().Password,
+		} {
+			if strings.Contains(s, "s3") {
+				t.Errorf("%s: %s leaks the password: %q", tc.name, format, s)
+			}
+		}
+		if !strings.Contains(tc.db.String(), "******") {
+			t.Errorf("%s: String() = %q, want the password masked", tc.name, tc.db.String())
+		}
+	}
+
+	cfg := config.Default()
+	cfg.DB.Password = "s3cret"
+	cfg.MemoryAPIKey = "tg_s3cret"
+	redacted := cfg.Redacted()
+	if redacted.DB.Password == cfg.DB.Password || redacted.MemoryAPIKey == cfg.MemoryAPIKey {
+		t.Errorf("Redacted() = %+v, want secrets masked", redacted)
+	}
+	if cfg.DB.Password != "s3cret" {
+		t.Error("Redacted() changed the config it was called on")
+	}
+
+	// A URL DSN without a password is left alone.
+	if dsn := "postgres://u@db/telegraph"; (config.DB{DSN: dsn}).String() != dsn {
+		t.Errorf("String() = %q, want %q", (config.DB{DSN: dsn}).String(), dsn)
+	}
+}
@@ -0,0 +1,104 @@
+// Package database opens the connection pool used by both binaries.
+package database
+
+import (
+	"context"
+	"fmt"
+	"github.com/coreyvan/backend-takehome/internal/config"
+	"github.com/glebarez/sqlite"
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+// Backoff between attempts to reach the database at startup.
+const (
+	initialBackoff = 500 * time.Millisecond
+	maxBackoff     = 10 * time.Second
+)
+
+// Open connects to the configured database and applies the pool settings.
+func Open(cfg config.DB) (*gorm.DB, error) {
+	dialector := postgres.Open(cfg.ConnectionString())
+	path, isSQLite := cfg.SQLitePath()
+	if isSQLite {
+		dialector = sqlite.Open(sqliteDSN(path))
+	}
+
+	db, err := gorm.Open(dialector, &gorm.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", cfg, err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("getting connection pool: %w", err)
+	}
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	if isSQLite {
+		// SQLite allows one writer at a time, and every connection to
+		// :memory: opens a separate database.
+		sqlDB.SetMaxOpenConns(1)
+		sqlDB.SetConnMaxLifetime(0)
+	}
+
+	return db, nil
+}
+
+// OpenWhenReady opens the database once it accepts connections, retrying
+// with exponential backoff for up to cfg.StartupTimeout or until ctx is done.
+// It lets the API start alongside the database rather than after it.
+func OpenWhenReady(ctx context.Context, cfg config.DB, log *zap.Logger) (*gorm.DB, error) {
+	deadline := time.Now().Add(cfg.StartupTimeout)
+	backoff := initialBackoff
+	for attempt := 1; ; attempt++ {
+		db, err := Open(cfg)
+		if err == nil {
+			if err = Ping(ctx, db); err == nil {
+				return db, nil
+			}
+			if sqlDB, dbErr := db.DB(); dbErr == nil {
+				sqlDB.Close()
+			}
+		}
+
+		if time.Now().Add(backoff).After(deadline) {
+			return nil, fmt.Errorf("database not ready after %d attempts: %w", attempt, err)
+		}
+		log.Sugar().Warnf("database not ready, retrying in %s: %v", backoff, err)
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("waiting for database: %w", ctx.Err())
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// Ping checks the database accepts queries.
+func Ping(ctx context.Context, db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("getting connection pool: %w", err)
+	}
+	if err := sqlDB.PingContext(ctx); err != nil {
+		return fmt.Errorf("pinging database: %w", err)
+	}
+	return nil
+}
+
+// sqliteDSN turns on foreign keys, which SQLite enforces per connection, and
+// waits on a locked database rather than failing.
+func sqliteDSN(path string) string {
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	return path + sep + "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"
+}
@@ -8,7 +8,10 @@ import (
 	"github.com/gocarina/gocsv"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"os"
+	"path/filepath"
+	"reflect"
 	"strconv"
 	"time"
 )
@@ -24,124 +27,284 @@ func NewIngester(db *gorm.DB, log *zap.Logger) *Ingester {
 	return &Ingester{db: db, log: log}
 }
 
+// ProcessEvents loads events.csv and, in the same transaction, queues webhook
+// deliveries of the events it adds or changes.
 func (i *Ingester) ProcessEvents(filename string) (int, error) {
-	i.db.Exec("DROP TABLE IF EXISTS events")
-	if err := i.db.AutoMigrate(&app.Event{}); err != nil {
-		return 0, fmt.Errorf("migrating events: %w", err)
+	toSave, err := ReadEvents(filename, i.log)
+	if err != nil {
+		return 0, err
+	}
+
+	var queued int
+	err = i.db.Transaction(func(tx *gorm.DB) error {
+		changed, err := replace(tx, app.DatasetEvents, toSave, func(r app.Event) string { return r.ID })
+		if err != nil {
+			return fmt.Errorf("saving events: %w", err)
+		}
+		queued, err = app.EnqueueDeliveries(tx, changed)
+		if err != nil {
+			return fmt.Errorf("queueing webhook deliveries: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if queued > 0 {
+		i.log.Sugar().Infof("queued %d webhook deliveries", queued)
+	}
+
+	return len(toSave), nil
+}
+
+func (i *Ingester) ProcessLocations(filename string) (int, error) {
+	toSave, err := ReadLocations(filename)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := replace(i.db, app.DatasetLocations, toSave, func(r app.Location) string { return r.ID }); err != nil {
+		return 0, fmt.Errorf("saving locations: %w", err)
+	}
+
+	return len(toSave), nil
+}
+func (i *Ingester) ProcessEquipment(filename string) (int, error) {
+	toSave, err := ReadEquipment(filename, i.log)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := replace(i.db, app.DatasetEquipment, toSave, func(r app.Equipment) string { return r.ID }); err != nil {
+		return 0, fmt.Errorf("saving equipment: %w", err)
+	}
+
+	return len(toSave), nil
+}
+func (i *Ingester) ProcessWaybills(filename string) (int, error) {
+	toSave, err := ReadWaybills(filename, i.log)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := replace(i.db, app.DatasetWaybills, toSave, func(r app.Waybill) string { return r.ID }); err != nil {
+		return 0, fmt.Errorf("saving waybills: %w", err)
+	}
+
+	return len(toSave), nil
+}
+
+// LoadMemoryStore reads locations.csv, equipment.csv, waybills.csv and
+// events.csv from dir into a MemoryStore.
+func LoadMemoryStore(dir string, log *zap.Logger) (*app.MemoryStore, error) {
+	locations, err := ReadLocations(filepath.Join(dir, "locations.csv"))
+	if err != nil {
+		return nil, fmt.Errorf("reading locations: %w", err)
+	}
+	equipment, err := ReadEquipment(filepath.Join(dir, "equipment.csv"), log)
+	if err != nil {
+		return nil, fmt.Errorf("reading equipment: %w", err)
+	}
+	waybills, err := ReadWaybills(filepath.Join(dir, "waybills.csv"), log)
+	if err != nil {
+		return nil, fmt.Errorf("reading waybills: %w", err)
+	}
+	events, err := ReadEvents(filepath.Join(dir, "events.csv"), log)
+	if err != nil {
+		return nil, fmt.Errorf("reading events: %w", err)
 	}
 
+	return app.NewMemoryStore(locations, equipment, waybills, events), nil
+}
+
+// ReadEvents parses an events CSV file, logging and skipping invalid lines.
+func ReadEvents(filename string, log *zap.Logger) ([]app.Event, error) {
 	//	parse from csv
 	f, err := os.Open(filename)
 	if err != nil {
-		return 0, fmt.Errorf("opening file: %w", err)
+		return nil, fmt.Errorf("opening file: %w", err)
 	}
 	defer f.Close()
 
 	lines, err := parseCSVLines(f)
 	if err != nil {
-		return 0, fmt.Errorf("parsing event lines: %w", err)
+		return nil, fmt.Errorf("parsing event lines: %w", err)
 	}
 
-	var toSave []app.Event
+	var events []app.Event
 	for k, l := range lines {
 		e, err := parseEvent(l)
 		if err != nil {
-			i.log.Sugar().Errorf("skipping line %d due to error: %v", k, err)
+			log.Sugar().Errorf("skipping line %d due to error: %v", k, err)
 			continue
 		}
-		toSave = append(toSave, *e)
+		events = append(events, *e)
 	}
 
-	i.db.Create(&toSave)
-
-	return len(toSave), nil
+	return events, nil
 }
-func (i *Ingester) ProcessLocations(filename string) (int, error) {
-	i.db.Exec("DROP TABLE IF EXISTS locations")
-	if err := i.db.AutoMigrate(&app.Location{}); err != nil {
-		return 0, fmt.Errorf("migrating locations: %w", err)
-	}
 
+// ReadLocations parses a locations CSV file.
+func ReadLocations(filename string) ([]app.Location, error) {
 	//	parse from csv
 	f, err := os.Open(filename)
 	if err != nil {
-		return 0, fmt.Errorf("opening file: %w", err)
+		return nil, fmt.Errorf("opening file: %w", err)
 	}
 	defer f.Close()
 
-	var toSave []app.Location
+	var locations []app.Location
 
-	if err := gocsv.UnmarshalFile(f, &toSave); err != nil {
-		return 0, fmt.Errorf("unmarshaling file: %w", err)
+	if err := gocsv.UnmarshalFile(f, &locations); err != nil {
+		return nil, fmt.Errorf("unmarshaling file: %w", err)
 	}
 
-	i.db.Create(&toSave)
-
-	return len(toSave), nil
+	return locations, nil
 }
-func (i *Ingester) ProcessEquipment(filename string) (int, error) {
-	i.db.Exec("DROP TABLE IF EXISTS equipment")
-	if err := i.db.AutoMigrate(&app.Equipment{}); err != nil {
-		return 0, fmt.Errorf("migrating equipment: %w", err)
-	}
 
+// ReadEquipment parses an equipment CSV file, logging and skipping invalid
+// lines.
+func ReadEquipment(filename string, log *zap.Logger) ([]app.Equipment, error) {
 	//	parse from csv
 	f, err := os.Open(filename)
 	if err != nil {
-		return 0, fmt.Errorf("opening file: %w", err)
+		return nil, fmt.Errorf("opening file: %w", err)
 	}
 	defer f.Close()
 
 	lines, err := parseCSVLines(f)
 	if err != nil {
-		return 0, fmt.Errorf("parsing equipment lines: %w", err)
+		return nil, fmt.Errorf("parsing equipment lines: %w", err)
 	}
 
-	var toSave []app.Equipment
+	var equipment []app.Equipment
 	for k, l := range lines {
 		e, err := parseEquipment(l)
 		if err != nil {
-			i.log.Sugar().Errorf("skipping line %d due to error: %v", k, err)
+			log.Sugar().Errorf("skipping line %d due to error: %v", k, err)
 			continue
 		}
-		toSave = append(toSave, *e)
+		equipment = append(equipment, *e)
 	}
 
-	i.db.Create(&toSave)
-
-	return len(toSave), nil
+	return equipment, nil
 }
-func (i *Ingester) ProcessWaybills(filename string) (int, error) {
-	i.db.Exec("DROP TABLE IF EXISTS waybills")
-	if err := i.db.AutoMigrate(&app.Waybill{}); err != nil {
-		return 0, fmt.Errorf("migrating waybills: %w", err)
-	}
 
+// ReadWaybills parses a waybills CSV file, logging and skipping invalid
+// lines.
+func ReadWaybills(filename string, log *zap.Logger) ([]app.Waybill, error) {
 	//	parse from csv
 	f, err := os.Open(filename)
 	if err != nil {
-		return 0, fmt.Errorf("opening file: %w", err)
+		return nil, fmt.Errorf("opening file: %w", err)
 	}
 	defer f.Close()
 
 	lines, err := parseCSVLines(f)
 	if err != nil {
-		return 0, fmt.Errorf("parsing waybill lines: %w", err)
+		return nil, fmt.Errorf("parsing waybill lines: %w", err)
 	}
 
-	var toSave []app.Waybill
+	var waybills []app.Waybill
 	for k, l := range lines {
 		w, err := parseWaybill(l)
 		if err != nil {
-			i.log.Sugar().Errorf("skipping line %d due to error: %v", k, err)
+			log.Sugar().Errorf("skipping line %d due to error: %v", k, err)
 			continue
 		}
-		toSave = append(toSave, *w)
+		waybills = append(waybills, *w)
 	}
 
-	i.db.Create(&toSave)
+	return waybills, nil
+}
 
-	return len(toSave), nil
+// replace makes rows the contents of a dataset's table in one transaction, so
+// a failed load leaves the previous data in place, and records the run of the
+// dataset. Rows are upserted and only those missing from the load are
+// deleted, so rows referencing them from other datasets are left alone.
+// Removing a row that's still referenced, like a waybill with events, fails.
+// It returns the rows the load added or changed.
+func replace[T any](db *gorm.DB, dataset string, rows []T, id func(T) string) ([]T, error) {
+	var changed []T
+	err := db.Transaction(func(tx *gorm.DB) error {
+		loaded := make(map[string]bool, len(rows))
+		ids := make([]string, 0, len(rows))
+		for _, r := range rows {
+			loaded[id(r)] = true
+			ids = append(ids, id(r))
+		}
+
+		current := make(map[string]T, len(rows))
+		for start := 0; start < len(ids); start += 500 {
+			end := start + 500
+			if end > len(ids) {
+				end = len(ids)
+			}
+			var found []T
+			if err := tx.Where("id IN ?", ids[start:end]).Find(&found).Error; err != nil {
+				return fmt.Errorf("reading current rows: %w", err)
+			}
+			for _, r := range found {
+				current[id(r)] = r
+			}
+		}
+		for _, r := range rows {
+			if old, ok := current[id(r)]; !ok || !sameRow(old, r) {
+				changed = append(changed, r)
+			}
+		}
+
+		if len(changed) > 0 {
+			if err := tx.Clauses(clause.OnConflict{UpdateAll: true}).CreateInBatches(changed, 500).Error; err != nil {
+				return fmt.Errorf("upserting rows: %w", err)
+			}
+		}
+
+		var existing []string
+		if err := tx.Model(new(T)).Pluck("id", &existing).Error; err != nil {
+			return fmt.Errorf("listing existing rows: %w", err)
+		}
+		var stale []string
+		for _, e := range existing {
+			if !loaded[e] {
+				stale = append(stale, e)
+			}
+		}
+		for start := 0; start < len(stale); start += 500 {
+			end := start + 500
+			if end > len(stale) {
+				end = len(stale)
+			}
+			if err := tx.Where("id IN ?", stale[start:end]).Delete(new(T)).Error; err != nil {
+				return fmt.Errorf("deleting %d rows missing from the load: %w", len(stale), err)
+			}
+		}
+
+		return app.RecordIngestRun(tx, dataset, len(rows))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return changed, nil
+}
+
+// sameRow reports whether two rows of a dataset hold the same values. Times
+// are compared as instants, since they may read back in another location.
+func sameRow(a, b interface{}) bool {
+	x, y := reflect.ValueOf(a), reflect.ValueOf(b)
+	for k := 0; k < x.NumField(); k++ {
+		f, g := x.Field(k).Interface(), y.Field(k).Interface()
+		if t, ok := f.(time.Time); ok {
+			if !t.Equal(g.(time.Time)) {
+				return false
+			}
+			continue
+		}
+		if f != g {
+			return false
+		}
+	}
+	return true
 }
 
 func parseCSVLines(f *os.File) ([][]string, error) {
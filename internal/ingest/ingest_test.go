@@ -0,0 +1,260 @@
+package ingest_test
+
+import (
+	"context"
+	"encoding/csv"
+	"github.com/coreyvan/backend-takehome/internal/app"
+	"github.com/coreyvan/backend-takehome/internal/config"
+	"github.com/coreyvan/backend-takehome/internal/database"
+	"github.com/coreyvan/backend-takehome/internal/ingest"
+	"github.com/coreyvan/backend-takehome/internal/migrate"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var data = filepath.Join("..", "..", "data")
+
+func newIngester(t *testing.T) (*ingest.Ingester, *gorm.DB) {
+	t.Helper()
+
+	cfg := config.Default()
+	cfg.DB.DSN = "sqlite://" + filepath.Join(t.TempDir(), "ingest.db")
+	db, err := database.Open(cfg.DB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := migrate.New(db, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return ingest.NewIngester(db, zap.NewNop()), db
+}
+
+// counts returns the rows in each dataset's table.
+func counts(t *testing.T, db *gorm.DB) map[string]int64 {
+	t.Helper()
+
+	n := make(map[string]int64)
+	for dataset, model := range map[string]interface{}{
+		app.DatasetLocations: &app.Location{},
+		app.DatasetEquipment: &app.Equipment{},
+		app.DatasetWaybills:  &app.Waybill{},
+		app.DatasetEvents:    &app.Event{},
+	} {
+		var c int64
+		if err := db.Model(model).Count(&c).Error; err != nil {
+			t.Fatal(err)
+		}
+		n[dataset] = c
+	}
+	return n
+}
+
+// TestReloadLeavesOtherDatasets checks reloading one dataset doesn't touch the
+// rows of the others, in particular that waybills don't take their events.
+func TestReloadLeavesOtherDatasets(t *testing.T) {
+	i, db := newIngester(t)
+	steps := []struct {
+		dataset string
+		process func(string) (int, error)
+	}{
+		{app.DatasetLocations, i.ProcessLocations},
+		{app.DatasetEquipment, i.ProcessEquipment},
+		{app.DatasetWaybills, i.ProcessWaybills},
+		{app.DatasetEvents, i.ProcessEvents},
+	}
+	for _, s := range steps {
+		if _, err := s.process(filepath.Join(data, s.dataset+".csv")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := counts(t, db)
+	for dataset, n := range want {
+		if n == 0 {
+			t.Fatalf("no %s were ingested", dataset)
+		}
+	}
+
+	for _, s := range steps {
+		if _, err := s.process(filepath.Join(data, s.dataset+".csv")); err != nil {
+			t.Fatalf("reloading %s: %v", s.dataset, err)
+		}
+		for dataset, n := range counts(t, db) {
+			if n != want[dataset] {
+				t.Errorf("after reloading %s: %d %s, want %d", s.dataset, n, dataset, want[dataset])
+			}
+		}
+	}
+}
+
+// TestReloadRemovesMissingRows checks rows missing from a load are deleted,
+// except for waybills that still have events.
+func TestReloadRemovesMissingRows(t *testing.T) {
+	i, db := newIngester(t)
+	if _, err := i.ProcessLocations(filepath.Join(data, "locations.csv")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := i.ProcessWaybills(filepath.Join(data, "waybills.csv")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := i.ProcessEvents(filepath.Join(data, "events.csv")); err != nil {
+		t.Fatal(err)
+	}
+	before := counts(t, db)
+
+	// Locations aren't referenced, so dropping one deletes it.
+	if _, err := i.ProcessLocations(without(t, filepath.Join(data, "locations.csv"), "2284")); err != nil {
+		t.Fatal(err)
+	}
+	if got := counts(t, db)[app.DatasetLocations]; got != before[app.DatasetLocations]-1 {
+		t.Errorf("%d locations after dropping one, want %d", got, before[app.DatasetLocations]-1)
+	}
+
+	// Waybill 7 has events, so dropping it fails and keeps them all.
+	if _, err := i.ProcessWaybills(without(t, filepath.Join(data, "waybills.csv"), "7")); err == nil {
+		t.Error("dropping a waybill with events succeeded")
+	}
+	after := counts(t, db)
+	for _, dataset := range []string{app.DatasetWaybills, app.DatasetEvents} {
+		if after[dataset] != before[dataset] {
+			t.Errorf("%d %s after a failed reload, want %d", after[dataset], dataset, before[dataset])
+		}
+	}
+}
+
+// without copies a CSV file without the row whose ID is id.
+func without(t *testing.T, filename, id string) string {
+	t.Helper()
+
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var kept []string
+	for _, line := range strings.SplitAfter(string(b), "\n") {
+		if !strings.HasPrefix(line, id+",") {
+			kept = append(kept, line)
+		}
+	}
+	if len(kept) == len(strings.SplitAfter(string(b), "\n")) {
+		t.Fatalf("%s has no row %s", filename, id)
+	}
+	out := filepath.Join(t.TempDir(), filepath.Base(filename))
+	if err := os.WriteFile(out, []byte(strings.Join(kept, "")), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+// TestProcessEventsQueuesChangedEvents checks ingestion only queues webhook
+// deliveries for events a load adds or changes, and that a failure to queue
+// them leaves the previous events in place.
+func TestProcessEventsQueuesChangedEvents(t *testing.T) {
+	i, db := newIngester(t)
+	events := filepath.Join(data, "events.csv")
+	for _, s := range []struct {
+		filename string
+		process  func(string) (int, error)
+	}{
+		{filepath.Join(data, "locations.csv"), i.ProcessLocations},
+		{filepath.Join(data, "equipment.csv"), i.ProcessEquipment},
+		{filepath.Join(data, "waybills.csv"), i.ProcessWaybills},
+		{events, i.ProcessEvents},
+	} {
+		if _, err := s.process(s.filename); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A subscription made after the events were posted isn't sent them when
+	// the same file is loaded again.
+	sub := app.Subscription{TargetURL: "https://example.com/hooks", Secret: "secret"}
+	if err := db.Create(&sub).Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := i.ProcessEvents(events); err != nil {
+		t.Fatal(err)
+	}
+	if queued := deliveries(t, db); len(queued) != 0 {
+		t.Errorf("reloading the same events queued %d deliveries, want none", len(queued))
+	}
+
+	// A corrected event is sent, and sent again when corrected once more.
+	for _, train := range []string{"T1", "T2"} {
+		if _, err := i.ProcessEvents(edited(t, events, "43126", 10, train)); err != nil {
+			t.Fatal(err)
+		}
+		queued := deliveries(t, db)
+		if len(queued) != 1 || queued[0].EventID != "43126" || queued[0].Status != app.DeliveryPending || !strings.Contains(queued[0].Payload, `"train_id":"`+train+`"`) {
+			t.Errorf("after correcting event 43126's train to %s, deliveries = %+v", train, queued)
+		}
+	}
+
+	// Events and their deliveries are saved together.
+	if err := db.Exec("ALTER TABLE deliveries RENAME TO deliveries_gone").Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := i.ProcessEvents(edited(t, events, "43126", 10, "T3")); err == nil {
+		t.Fatal("loading events without a deliveries table succeeded")
+	}
+	var event app.Event
+	if err := db.First(&event, "id = ?", "43126").Error; err != nil {
+		t.Fatal(err)
+	}
+	if event.TrainID != "T2" {
+		t.Errorf("event 43126's train is %q after a failed load, want T2", event.TrainID)
+	}
+}
+
+func deliveries(t *testing.T, db *gorm.DB) []app.Delivery {
+	t.Helper()
+
+	var queued []app.Delivery
+	if err := db.Order("id").Find(&queued).Error; err != nil {
+		t.Fatal(err)
+	}
+	return queued
+}
+
+// edited copies a CSV file, setting a column of the row whose ID is id.
+func edited(t *testing.T, filename, id string, column int, value string) string {
+	t.Helper()
+
+	f, err := os.Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, row := range rows {
+		if row[0] == id {
+			row[column], found = value, true
+		}
+	}
+	if !found {
+		t.Fatalf("%s has no row %s", filename, id)
+	}
+
+	out := filepath.Join(t.TempDir(), filepath.Base(filename))
+	o, err := os.Create(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer o.Close()
+	w := csv.NewWriter(o)
+	if err := w.WriteAll(rows); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
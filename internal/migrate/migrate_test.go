@@ -0,0 +1,162 @@
+package migrate_test
+
+import (
+	"context"
+	"github.com/coreyvan/backend-takehome/internal/config"
+	"github.com/coreyvan/backend-takehome/internal/database"
+	"github.com/coreyvan/backend-takehome/internal/migrate"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"path/filepath"
+	"testing"
+)
+
+func newMigrator(t *testing.T) (*migrate.Migrator, *gorm.DB) {
+	t.Helper()
+
+	cfg := config.Default()
+	cfg.DB.DSN = "sqlite://" + filepath.Join(t.TempDir(), "migrate.db")
+	db, err := database.Open(cfg.DB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := migrate.New(db, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m, db
+}
+
+// checkApplied checks exactly the migrations up to and including version are
+// applied, and that Pending and RequireCurrent agree.
+func checkApplied(t *testing.T, m *migrate.Migrator, version int) {
+	t.Helper()
+	ctx := context.Background()
+
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var unapplied int
+	for _, s := range statuses {
+		if applied := s.AppliedAt != nil; applied != (s.Version <= version) {
+			t.Errorf("migration %d_%s applied = %v with migrations up to %d applied", s.Version, s.Name, applied, version)
+		}
+		if s.AppliedAt == nil {
+			unapplied++
+		}
+	}
+
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != unapplied {
+		t.Errorf("%d pending migrations, want %d", len(pending), unapplied)
+	}
+	if err := m.RequireCurrent(ctx); (err == nil) != (unapplied == 0) {
+		t.Errorf("RequireCurrent with %d pending migrations: err = %v", unapplied, err)
+	}
+}
+
+// TestLoad checks the embedded migrations of each dialect are numbered from 1
+// without gaps and each has its scripts.
+func TestLoad(t *testing.T) {
+	for _, dialect := range []string{"postgres", "sqlite"} {
+		all, err := migrate.Load(dialect)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(all) == 0 {
+			t.Fatalf("no %s migrations", dialect)
+		}
+		for k, m := range all {
+			if m.Version != k+1 {
+				t.Errorf("%s migration %d_%s is number %d", dialect, m.Version, m.Name, k+1)
+			}
+			if m.Name == "" || m.Up == "" || m.Down == "" {
+				t.Errorf("%s migration %d is missing its name or a script: %+v", dialect, m.Version, m)
+			}
+		}
+	}
+
+	if _, err := migrate.Load("oracle"); err == nil {
+		t.Error("loading migrations for an unknown dialect succeeded")
+	}
+}
+
+// TestUpDownUp applies every SQLite migration, rolls them all back one at a
+// time, checking each down script runs, and applies them again.
+func TestUpDownUp(t *testing.T) {
+	ctx := context.Background()
+	m, db := newMigrator(t)
+	all, err := migrate.Load("sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	latest := all[len(all)-1].Version
+	checkApplied(t, m, 0)
+
+	applied, err := m.Up(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != len(all) {
+		t.Errorf("applied %d migrations, want %d", len(applied), len(all))
+	}
+	checkApplied(t, m, latest)
+	if again, err := m.Up(ctx); err != nil || len(again) != 0 {
+		t.Errorf("applying a current schema applied %d migrations, err = %v", len(again), err)
+	}
+
+	// Tracking rows are kept until their own migration is rolled back.
+	for _, stmt := range []string{
+		"INSERT INTO waybills (id, equipment_id) VALUES ('1', 'TILX200001')",
+		"INSERT INTO events (id, equipment_id, waybill_id) VALUES ('1', 'TILX200001', '1')",
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for k := len(all) - 1; k >= 0; k-- {
+		rolledBack, err := m.Down(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rolledBack.Version != all[k].Version {
+			t.Fatalf("rolled back migration %d, want %d", rolledBack.Version, all[k].Version)
+		}
+		version := 0
+		if k > 0 {
+			version = all[k-1].Version
+		}
+		checkApplied(t, m, version)
+
+		if db.Migrator().HasTable("events") {
+			var events int64
+			if err := db.Table("events").Count(&events).Error; err != nil {
+				t.Fatal(err)
+			}
+			if events != 1 {
+				t.Errorf("%d events after rolling back migration %d, want 1", events, rolledBack.Version)
+			}
+		}
+	}
+	if _, err := m.Down(ctx); err == nil {
+		t.Error("rolling back with no migrations applied succeeded")
+	}
+
+	var tables []string
+	if err := db.Raw("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT IN ('schema_migrations', 'sqlite_sequence')").Scan(&tables).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(tables) != 0 {
+		t.Errorf("tables %v are left after rolling back every migration", tables)
+	}
+
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatalf("applying migrations again: %v", err)
+	}
+	checkApplied(t, m, latest)
+}
@@ -0,0 +1,82 @@
+CREATE TABLE IF NOT EXISTS locations (
+    id        text PRIMARY KEY,
+    city      text,
+    city_long text,
+    station   text,
+    fsac      text,
+    scac      text,
+    splc      text,
+    state     text,
+    timezone  text,
+    longitude double precision,
+    latitude  double precision,
+    country   text
+);
+
+CREATE TABLE IF NOT EXISTS equipment (
+    id               text PRIMARY KEY,
+    customer         text,
+    fleet            text,
+    equipment_id     text,
+    equipment_status text,
+    date_added       timestamptz,
+    date_removed     timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_equipment_equipment_id ON equipment (equipment_id);
+CREATE INDEX IF NOT EXISTS idx_equipment_customer ON equipment (customer);
+
+CREATE TABLE IF NOT EXISTS waybills (
+    id                     text PRIMARY KEY,
+    equipment_id           text,
+    waybill_date           timestamptz,
+    waybill_number         text,
+    created_date           timestamptz,
+    billing_road_mark_name text,
+    waybill_source_code    text,
+    load_empty_status      text,
+    origin_mark_name       text,
+    destination_mark_name  text,
+    sending_road_mark      text,
+    bill_of_lading_number  text,
+    bill_of_lading_date    timestamptz,
+    equipment_weight       bigint,
+    tare_weight            bigint,
+    allowable_weight       bigint,
+    dunnage_weight         bigint,
+    equipment_weight_code  text,
+    commodity_code         text,
+    commodity_description  text,
+    origin_id              text,
+    destination_id         text,
+    routes                 text,
+    parties                text
+);
+
+CREATE INDEX IF NOT EXISTS idx_waybills_equipment_id ON waybills (equipment_id);
+
+-- Events reference locations that aren't in the location list, so only the
+-- waybill relation is enforced. Removing a waybill removes its events.
+CREATE TABLE IF NOT EXISTS events (
+    id                       text PRIMARY KEY,
+    equipment_id             text,
+    sighting_date            timestamptz,
+    sighting_event_code      text,
+    reporting_railroad_scac  text,
+    posting_date             timestamptz,
+    from_mark_id             text,
+    load_empty_status        text,
+    sighting_claim_code      text,
+    sighting_event_code_text text,
+    train_id                 text,
+    train_alpha_code         text,
+    location_id              text,
+    waybill_id               text
+);
+
+ALTER TABLE events DROP CONSTRAINT IF EXISTS fk_events_waybill;
+ALTER TABLE events ADD CONSTRAINT fk_events_waybill FOREIGN KEY (waybill_id) REFERENCES waybills (id) ON DELETE RESTRICT;
+
+CREATE INDEX IF NOT EXISTS idx_events_waybill_id ON events (waybill_id);
+CREATE INDEX IF NOT EXISTS idx_events_posting_date ON events (posting_date, id);
+CREATE INDEX IF NOT EXISTS idx_events_equipment_id ON events (equipment_id, sighting_date);
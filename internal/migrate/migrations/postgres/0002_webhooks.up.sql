@@ -0,0 +1,52 @@
+CREATE TABLE IF NOT EXISTS subscriptions (
+    id           bigserial PRIMARY KEY,
+    target_url   text NOT NULL,
+    secret       text NOT NULL,
+    waybill_id   text,
+    equipment_id text,
+    event_codes  text,
+    customer     text,
+    created_at   timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_subscriptions_customer ON subscriptions (customer);
+
+CREATE TABLE IF NOT EXISTS deliveries (
+    id              bigserial PRIMARY KEY,
+    subscription_id bigint NOT NULL REFERENCES subscriptions (id) ON DELETE CASCADE,
+    event_id        text NOT NULL,
+    payload         text,
+    status          text,
+    attempts        bigint,
+    next_attempt_at timestamptz,
+    last_error      text,
+    created_at      timestamptz,
+    updated_at      timestamptz
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_delivery_subscription_event ON deliveries (subscription_id, event_id);
+CREATE INDEX IF NOT EXISTS idx_deliveries_due ON deliveries (status, next_attempt_at);
+
+CREATE TABLE IF NOT EXISTS delivery_attempts (
+    id           bigserial PRIMARY KEY,
+    delivery_id  bigint NOT NULL REFERENCES deliveries (id) ON DELETE CASCADE,
+    attempt      bigint,
+    status_code  bigint,
+    error        text,
+    duration_ms  bigint,
+    attempted_at timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_delivery_attempts_delivery_id ON delivery_attempts (delivery_id);
+
+CREATE TABLE IF NOT EXISTS dead_letters (
+    id              bigserial PRIMARY KEY,
+    delivery_id     bigint NOT NULL UNIQUE REFERENCES deliveries (id) ON DELETE CASCADE,
+    subscription_id bigint NOT NULL REFERENCES subscriptions (id) ON DELETE CASCADE,
+    event_id        text,
+    payload         text,
+    last_error      text,
+    created_at      timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_dead_letters_subscription_id ON dead_letters (subscription_id);
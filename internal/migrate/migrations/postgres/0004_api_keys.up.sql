@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS api_keys (
+    id           bigserial PRIMARY KEY,
+    name         text,
+    prefix       text NOT NULL,
+    hash         text NOT NULL UNIQUE,
+    customers    text NOT NULL,
+    created_at   timestamptz,
+    last_used_at timestamptz,
+    revoked_at   timestamptz
+);
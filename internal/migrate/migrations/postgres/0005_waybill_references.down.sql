@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_waybills_bill_of_lading_number;
+DROP INDEX IF EXISTS idx_waybills_waybill_number;
@@ -0,0 +1,10 @@
+-- Each load of a dataset is recorded so HTTP responses built from it can be
+-- revalidated by the latest run.
+CREATE TABLE IF NOT EXISTS ingest_runs (
+    id          bigserial PRIMARY KEY,
+    dataset     text NOT NULL,
+    row_count   integer NOT NULL,
+    ingested_at timestamptz NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_ingest_runs_dataset ON ingest_runs (dataset, id);
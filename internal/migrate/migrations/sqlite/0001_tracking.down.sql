@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS events;
+DROP TABLE IF EXISTS waybills;
+DROP TABLE IF EXISTS equipment;
+DROP TABLE IF EXISTS locations;
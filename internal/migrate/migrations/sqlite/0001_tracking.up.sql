@@ -0,0 +1,79 @@
+CREATE TABLE IF NOT EXISTS locations (
+    id        text PRIMARY KEY,
+    city      text,
+    city_long text,
+    station   text,
+    fsac      text,
+    scac      text,
+    splc      text,
+    state     text,
+    timezone  text,
+    longitude real,
+    latitude  real,
+    country   text
+);
+
+CREATE TABLE IF NOT EXISTS equipment (
+    id               text PRIMARY KEY,
+    customer         text,
+    fleet            text,
+    equipment_id     text,
+    equipment_status text,
+    date_added       datetime,
+    date_removed     datetime
+);
+
+CREATE INDEX IF NOT EXISTS idx_equipment_equipment_id ON equipment (equipment_id);
+CREATE INDEX IF NOT EXISTS idx_equipment_customer ON equipment (customer);
+
+CREATE TABLE IF NOT EXISTS waybills (
+    id                     text PRIMARY KEY,
+    equipment_id           text,
+    waybill_date           datetime,
+    waybill_number         text,
+    created_date           datetime,
+    billing_road_mark_name text,
+    waybill_source_code    text,
+    load_empty_status      text,
+    origin_mark_name       text,
+    destination_mark_name  text,
+    sending_road_mark      text,
+    bill_of_lading_number  text,
+    bill_of_lading_date    datetime,
+    equipment_weight       integer,
+    tare_weight            integer,
+    allowable_weight       integer,
+    dunnage_weight         integer,
+    equipment_weight_code  text,
+    commodity_code         text,
+    commodity_description  text,
+    origin_id              text,
+    destination_id         text,
+    routes                 text,
+    parties                text
+);
+
+CREATE INDEX IF NOT EXISTS idx_waybills_equipment_id ON waybills (equipment_id);
+
+-- Events reference locations that aren't in the location list, so only the
+-- waybill relation is enforced. Removing a waybill removes its events.
+CREATE TABLE IF NOT EXISTS events (
+    id                       text PRIMARY KEY,
+    equipment_id             text,
+    sighting_date            datetime,
+    sighting_event_code      text,
+    reporting_railroad_scac  text,
+    posting_date             datetime,
+    from_mark_id             text,
+    load_empty_status        text,
+    sighting_claim_code      text,
+    sighting_event_code_text text,
+    train_id                 text,
+    train_alpha_code         text,
+    location_id              text,
+    waybill_id               text REFERENCES waybills (id) ON DELETE RESTRICT
+);
+
+CREATE INDEX IF NOT EXISTS idx_events_waybill_id ON events (waybill_id);
+CREATE INDEX IF NOT EXISTS idx_events_posting_date ON events (posting_date, id);
+CREATE INDEX IF NOT EXISTS idx_events_equipment_id ON events (equipment_id, sighting_date);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS dead_letters;
+DROP TABLE IF EXISTS delivery_attempts;
+DROP TABLE IF EXISTS deliveries;
+DROP TABLE IF EXISTS subscriptions;
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS alerts (
+    id              integer PRIMARY KEY,
+    key             text NOT NULL,
+    rule            text NOT NULL,
+    type            text NOT NULL,
+    severity        text,
+    status          text NOT NULL,
+    waybill_id      text,
+    equipment_id    text,
+    location_id     text,
+    event_id        text,
+    message         text,
+    opened_at       datetime,
+    last_seen_at    datetime,
+    acknowledged_at datetime,
+    resolved_at     datetime
+);
+
+CREATE INDEX IF NOT EXISTS idx_alerts_status_key ON alerts (status, key);
+CREATE INDEX IF NOT EXISTS idx_alerts_waybill_id ON alerts (waybill_id);
+CREATE INDEX IF NOT EXISTS idx_alerts_rule ON alerts (rule);
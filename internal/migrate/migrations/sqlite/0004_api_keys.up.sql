@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS api_keys (
+    id           integer PRIMARY KEY,
+    name         text,
+    prefix       text NOT NULL,
+    hash         text NOT NULL UNIQUE,
+    customers    text NOT NULL,
+    created_at   datetime,
+    last_used_at datetime,
+    revoked_at   datetime
+);
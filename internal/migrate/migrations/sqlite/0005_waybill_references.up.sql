@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS idx_waybills_waybill_number ON waybills (waybill_number);
+CREATE INDEX IF NOT EXISTS idx_waybills_bill_of_lading_number ON waybills (bill_of_lading_number);
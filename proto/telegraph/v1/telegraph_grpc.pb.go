@@ -0,0 +1,431 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v3.21.5
+// source: telegraph/v1/telegraph.proto
+
+package telegraphv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// TelegraphClient is the client API for Telegraph service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type TelegraphClient interface {
+	ListEquipment(ctx context.Context, in *ListEquipmentRequest, opts ...grpc.CallOption) (*ListEquipmentResponse, error)
+	ListEvents(ctx context.Context, in *ListEventsRequest, opts ...grpc.CallOption) (*ListEventsResponse, error)
+	ListLocations(ctx context.Context, in *ListLocationsRequest, opts ...grpc.CallOption) (*ListLocationsResponse, error)
+	ListWaybills(ctx context.Context, in *ListWaybillsRequest, opts ...grpc.CallOption) (*ListWaybillsResponse, error)
+	GetWaybill(ctx context.Context, in *GetWaybillRequest, opts ...grpc.CallOption) (*Waybill, error)
+	// GetWaybillEquipment returns the equipment record in effect for the
+	// shipment, if the key may see it.
+	GetWaybillEquipment(ctx context.Context, in *GetWaybillRequest, opts ...grpc.CallOption) (*ListEquipmentResponse, error)
+	ListWaybillEvents(ctx context.Context, in *ListWaybillEventsRequest, opts ...grpc.CallOption) (*ListEventsResponse, error)
+	// GetWaybillLocations returns the waybill's origin and destination.
+	GetWaybillLocations(ctx context.Context, in *GetWaybillRequest, opts ...grpc.CallOption) (*ListLocationsResponse, error)
+	// StreamEvents sends events as they're posted, polling for new ones until
+	// the call is cancelled.
+	StreamEvents(ctx context.Context, in *StreamEventsRequest, opts ...grpc.CallOption) (Telegraph_StreamEventsClient, error)
+}
+
+type telegraphClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTelegraphClient(cc grpc.ClientConnInterface) TelegraphClient {
+	return &telegraphClient{cc}
+}
+
+func (c *telegraphClient) ListEquipment(ctx context.Context, in *ListEquipmentRequest, opts ...grpc.CallOption) (*ListEquipmentResponse, error) {
+	out := new(ListEquipmentResponse)
+	err := c.cc.Invoke(ctx, "/telegraph.v1.Telegraph/ListEquipment", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *telegraphClient) ListEvents(ctx context.Context, in *ListEventsRequest, opts ...grpc.CallOption) (*ListEventsResponse, error) {
+	out := new(ListEventsResponse)
+	err := c.cc.Invoke(ctx, "/telegraph.v1.Telegraph/ListEvents", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *telegraphClient) ListLocations(ctx context.Context, in *ListLocationsRequest, opts ...grpc.CallOption) (*ListLocationsResponse, error) {
+	out := new(ListLocationsResponse)
+	err := c.cc.Invoke(ctx, "/telegraph.v1.Telegraph/ListLocations", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *telegraphClient) ListWaybills(ctx context.Context, in *ListWaybillsRequest, opts ...grpc.CallOption) (*ListWaybillsResponse, error) {
+	out := new(ListWaybillsResponse)
+	err := c.cc.Invoke(ctx, "/telegraph.v1.Telegraph/ListWaybills", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *telegraphClient) GetWaybill(ctx context.Context, in *GetWaybillRequest, opts ...grpc.CallOption) (*Waybill, error) {
+	out := new(Waybill)
+	err := c.cc.Invoke(ctx, "/telegraph.v1.Telegraph/GetWaybill", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *telegraphClient) GetWaybillEquipment(ctx context.Context, in *GetWaybillRequest, opts ...grpc.CallOption) (*ListEquipmentResponse, error) {
+	out := new(ListEquipmentResponse)
+	err := c.cc.Invoke(ctx, "/telegraph.v1.Telegraph/GetWaybillEquipment", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *telegraphClient) ListWaybillEvents(ctx context.Context, in *ListWaybillEventsRequest, opts ...grpc.CallOption) (*ListEventsResponse, error) {
+	out := new(ListEventsResponse)
+	err := c.cc.Invoke(ctx, "/telegraph.v1.Telegraph/ListWaybillEvents", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *telegraphClient) GetWaybillLocations(ctx context.Context, in *GetWaybillRequest, opts ...grpc.CallOption) (*ListLocationsResponse, error) {
+	out := new(ListLocationsResponse)
+	err := c.cc.Invoke(ctx, "/telegraph.v1.Telegraph/GetWaybillLocations", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *telegraphClient) StreamEvents(ctx context.Context, in *StreamEventsRequest, opts ...grpc.CallOption) (Telegraph_StreamEventsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Telegraph_ServiceDesc.Streams[0], "/telegraph.v1.Telegraph/StreamEvents", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &telegraphStreamEventsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Telegraph_StreamEventsClient interface {
+	Recv() (*StreamedEvent, error)
+	grpc.ClientStream
+}
+
+type telegraphStreamEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *telegraphStreamEventsClient) Recv() (*StreamedEvent, error) {
+	m := new(StreamedEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// TelegraphServer is the server API for Telegraph service.
+// All implementations must embed UnimplementedTelegraphServer
+// for forward compatibility
+type TelegraphServer interface {
+	ListEquipment(context.Context, *ListEquipmentRequest) (*ListEquipmentResponse, error)
+	ListEvents(context.Context, *ListEventsRequest) (*ListEventsResponse, error)
+	ListLocations(context.Context, *ListLocationsRequest) (*ListLocationsResponse, error)
+	ListWaybills(context.Context, *ListWaybillsRequest) (*ListWaybillsResponse, error)
+	GetWaybill(context.Context, *GetWaybillRequest) (*Waybill, error)
+	// GetWaybillEquipment returns the equipment record in effect for the
+	// shipment, if the key may see it.
+	GetWaybillEquipment(context.Context, *GetWaybillRequest) (*ListEquipmentResponse, error)
+	ListWaybillEvents(context.Context, *ListWaybillEventsRequest) (*ListEventsResponse, error)
+	// GetWaybillLocations returns the waybill's origin and destination.
+	GetWaybillLocations(context.Context, *GetWaybillRequest) (*ListLocationsResponse, error)
+	// StreamEvents sends events as they're posted, polling for new ones until
+	// the call is cancelled.
+	StreamEvents(*StreamEventsRequest, Telegraph_StreamEventsServer) error
+	mustEmbedUnimplementedTelegraphServer()
+}
+
+// UnimplementedTelegraphServer must be embedded to have forward compatible implementations.
+type UnimplementedTelegraphServer struct {
+}
+
+func (UnimplementedTelegraphServer) ListEquipment(context.Context, *ListEquipmentRequest) (*ListEquipmentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListEquipment not implemented")
+}
+func (UnimplementedTelegraphServer) ListEvents(context.Context, *ListEventsRequest) (*ListEventsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListEvents not implemented")
+}
+func (UnimplementedTelegraphServer) ListLocations(context.Context, *ListLocationsRequest) (*ListLocationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListLocations not implemented")
+}
+func (UnimplementedTelegraphServer) ListWaybills(context.Context, *ListWaybillsRequest) (*ListWaybillsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListWaybills not implemented")
+}
+func (UnimplementedTelegraphServer) GetWaybill(context.Context, *GetWaybillRequest) (*Waybill, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWaybill not implemented")
+}
+func (UnimplementedTelegraphServer) GetWaybillEquipment(context.Context, *GetWaybillRequest) (*ListEquipmentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWaybillEquipment not implemented")
+}
+func (UnimplementedTelegraphServer) ListWaybillEvents(context.Context, *ListWaybillEventsRequest) (*ListEventsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListWaybillEvents not implemented")
+}
+func (UnimplementedTelegraphServer) GetWaybillLocations(context.Context, *GetWaybillRequest) (*ListLocationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWaybillLocations not implemented")
+}
+func (UnimplementedTelegraphServer) StreamEvents(*StreamEventsRequest, Telegraph_StreamEventsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamEvents not implemented")
+}
+func (UnimplementedTelegraphServer) mustEmbedUnimplementedTelegraphServer() {}
+
+// UnsafeTelegraphServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TelegraphServer will
+// result in compilation errors.
+type UnsafeTelegraphServer interface {
+	mustEmbedUnimplementedTelegraphServer()
+}
+
+func RegisterTelegraphServer(s grpc.ServiceRegistrar, srv TelegraphServer) {
+	s.RegisterService(&Telegraph_ServiceDesc, srv)
+}
+
+func _Telegraph_ListEquipment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListEquipmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TelegraphServer).ListEquipment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/telegraph.v1.Telegraph/ListEquipment",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TelegraphServer).ListEquipment(ctx, req.(*ListEquipmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Telegraph_ListEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TelegraphServer).ListEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/telegraph.v1.Telegraph/ListEvents",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TelegraphServer).ListEvents(ctx, req.(*ListEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Telegraph_ListLocations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListLocationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TelegraphServer).ListLocations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/telegraph.v1.Telegraph/ListLocations",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TelegraphServer).ListLocations(ctx, req.(*ListLocationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Telegraph_ListWaybills_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListWaybillsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TelegraphServer).ListWaybills(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/telegraph.v1.Telegraph/ListWaybills",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TelegraphServer).ListWaybills(ctx, req.(*ListWaybillsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Telegraph_GetWaybill_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetWaybillRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TelegraphServer).GetWaybill(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/telegraph.v1.Telegraph/GetWaybill",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TelegraphServer).GetWaybill(ctx, req.(*GetWaybillRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Telegraph_GetWaybillEquipment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetWaybillRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TelegraphServer).GetWaybillEquipment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/telegraph.v1.Telegraph/GetWaybillEquipment",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TelegraphServer).GetWaybillEquipment(ctx, req.(*GetWaybillRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Telegraph_ListWaybillEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListWaybillEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TelegraphServer).ListWaybillEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/telegraph.v1.Telegraph/ListWaybillEvents",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TelegraphServer).ListWaybillEvents(ctx, req.(*ListWaybillEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Telegraph_GetWaybillLocations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetWaybillRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TelegraphServer).GetWaybillLocations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/telegraph.v1.Telegraph/GetWaybillLocations",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TelegraphServer).GetWaybillLocations(ctx, req.(*GetWaybillRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Telegraph_StreamEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TelegraphServer).StreamEvents(m, &telegraphStreamEventsServer{stream})
+}
+
+type Telegraph_StreamEventsServer interface {
+	Send(*StreamedEvent) error
+	grpc.ServerStream
+}
+
+type telegraphStreamEventsServer struct {
+	grpc.ServerStream
+}
+
+func (x *telegraphStreamEventsServer) Send(m *StreamedEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// Telegraph_ServiceDesc is the grpc.ServiceDesc for Telegraph service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Telegraph_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "telegraph.v1.Telegraph",
+	HandlerType: (*TelegraphServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListEquipment",
+			Handler:    _Telegraph_ListEquipment_Handler,
+		},
+		{
+			MethodName: "ListEvents",
+			Handler:    _Telegraph_ListEvents_Handler,
+		},
+		{
+			MethodName: "ListLocations",
+			Handler:    _Telegraph_ListLocations_Handler,
+		},
+		{
+			MethodName: "ListWaybills",
+			Handler:    _Telegraph_ListWaybills_Handler,
+		},
+		{
+			MethodName: "GetWaybill",
+			Handler:    _Telegraph_GetWaybill_Handler,
+		},
+		{
+			MethodName: "GetWaybillEquipment",
+			Handler:    _Telegraph_GetWaybillEquipment_Handler,
+		},
+		{
+			MethodName: "ListWaybillEvents",
+			Handler:    _Telegraph_ListWaybillEvents_Handler,
+		},
+		{
+			MethodName: "GetWaybillLocations",
+			Handler:    _Telegraph_GetWaybillLocations_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamEvents",
+			Handler:       _Telegraph_StreamEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "telegraph/v1/telegraph.proto",
+}